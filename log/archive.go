@@ -0,0 +1,432 @@
+package log
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// 切割后文件的压缩算法
+const (
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// compressedExts 压缩后文件的扩展名
+var compressedExts = map[string]string{
+	CompressionGzip: ".gz",
+	CompressionZstd: ".zst",
+}
+
+// 上传失败时的重试次数及间隔
+var (
+	UploadAttempts = 3
+	UploadBackoff  = time.Second
+)
+
+// RotateHook 文件切割完成后的回调，oldPath为切割出的文件经压缩、归档后的最终路径，newPath为新的日志文件路径
+type RotateHook func(oldPath, newPath string)
+
+// ObjectStore 切割后文件的上传目标，如S3、OSS等对象存储
+type ObjectStore interface {
+	Upload(ctx context.Context, key string, body io.Reader) error
+}
+
+var (
+	rotateLock   sync.RWMutex
+	rotateHooks  = make(map[string]RotateHook)
+	objectStores = make(map[string]ObjectStore)
+)
+
+// RegisterRotateHook 注册文件切割回调，所有logger的日志文件切割后都会调用，同名回调会被替换；回调在后台协程中执行
+func RegisterRotateHook(name string, hook RotateHook) {
+	rotateLock.Lock()
+	defer rotateLock.Unlock()
+	rotateHooks[name] = hook
+}
+
+// RemoveRotateHook 移除文件切割回调
+func RemoveRotateHook(name string) {
+	rotateLock.Lock()
+	defer rotateLock.Unlock()
+	delete(rotateHooks, name)
+}
+
+// RegisterObjectStore 注册对象存储，注册后可在FileLogConfig.Upload中通过name引用
+func RegisterObjectStore(name string, store ObjectStore) error {
+	rotateLock.Lock()
+	defer rotateLock.Unlock()
+	if name == "" {
+		return errors.New("log: object store name is required")
+	}
+	if _, ok := objectStores[name]; ok {
+		return fmt.Errorf("log: object store %q is already registered", name)
+	}
+	objectStores[name] = store
+	return nil
+}
+
+// UnregisterObjectStore 注销对象存储，注销后名称可重新注册，引用该名称的上传将失败并保留文件
+func UnregisterObjectStore(name string) error {
+	rotateLock.Lock()
+	defer rotateLock.Unlock()
+	if _, ok := objectStores[name]; !ok {
+		return fmt.Errorf("log: object store %q is not registered", name)
+	}
+	delete(objectStores, name)
+	return nil
+}
+
+func lookupObjectStore(name string) (ObjectStore, bool) {
+	rotateLock.RLock()
+	defer rotateLock.RUnlock()
+	store, ok := objectStores[name]
+	return store, ok
+}
+
+// compression 返回压缩算法，未配置Compression时按Compress决定是否使用gzip
+func (c FileLogConfig) compression() string {
+	if c.Compression == "" && c.Compress {
+		return CompressionGzip
+	}
+	return c.Compression
+}
+
+// postRotate 切割后处理，依次压缩、计算校验和、移动到归档目录、上传，最后调用回调并清理过期文件
+type postRotate struct {
+	config FileLogConfig
+	// dir及match 用于查找切割出的历史文件
+	dir   string
+	match *regexp.Regexp
+	now   func() time.Time
+
+	mu      sync.Mutex
+	pending sync.WaitGroup
+	// queued 已排队或处理中的文件，避免重复处理
+	queuedMu sync.Mutex
+	queued   map[string]bool
+}
+
+func newPostRotate(config FileLogConfig, dir string, match *regexp.Regexp) *postRotate {
+	return &postRotate{config: config, dir: dir, match: match, now: time.Now, queued: make(map[string]bool)}
+}
+
+// process 在后台处理切割出的文件，已排队的文件忽略
+func (p *postRotate) process(rotated, active string) {
+	p.queuedMu.Lock()
+	if p.queued[rotated] {
+		p.queuedMu.Unlock()
+		return
+	}
+	p.queued[rotated] = true
+	p.queuedMu.Unlock()
+	p.pending.Add(1)
+	go func() {
+		defer p.pending.Done()
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.run(rotated, active)
+		p.queuedMu.Lock()
+		delete(p.queued, rotated)
+		p.queuedMu.Unlock()
+	}()
+}
+
+// unprocessed 查找未经切割后处理的文件，如进程重启或Reopen跨越时间段时上一时间段的文件；
+// 仅在压缩、移动到其他归档目录或计算校验和时可以判断文件是否已处理，其余配置返回nil
+func (p *postRotate) unprocessed(active string) []string {
+	moved := p.config.compression() != "" ||
+		(p.config.ArchiveDir != "" && filepath.Clean(p.config.ArchiveDir) != filepath.Clean(p.dir))
+	if !moved && !p.config.Checksum {
+		return nil
+	}
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		path := filepath.Join(p.dir, entry.Name())
+		if entry.IsDir() || path == active || !p.match.MatchString(entry.Name()) {
+			continue
+		}
+		switch filepath.Ext(path) {
+		case ".gz", ".zst":
+			continue
+		}
+		if !moved && fileExists(path+".sha256") {
+			continue
+		}
+		names = append(names, path)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// wait 等待处理中的文件完成
+func (p *postRotate) wait() {
+	p.pending.Wait()
+}
+
+func (p *postRotate) run(rotated, active string) {
+	final, err := p.archive(rotated)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v log: post rotate %s failed: %v\n", time.Now(), rotated, err)
+	}
+	rotateLock.RLock()
+	hooks := make([]RotateHook, 0, len(rotateHooks))
+	for _, hook := range rotateHooks {
+		hooks = append(hooks, hook)
+	}
+	rotateLock.RUnlock()
+	for _, hook := range hooks {
+		hook(final, active)
+	}
+	p.removeExpired(active)
+}
+
+// archive 执行压缩、校验和、归档及上传，返回文件的最终路径；出错时停止后续步骤，文件保留在当前位置
+func (p *postRotate) archive(name string) (string, error) {
+	if compression := p.config.compression(); compression != "" {
+		compressed, err := compressFile(name, compression)
+		if err != nil {
+			return name, err
+		}
+		name = compressed
+	}
+	files := []string{name}
+	if p.config.Checksum {
+		sum, err := writeChecksum(name)
+		if err != nil {
+			return name, err
+		}
+		files = append(files, sum)
+	}
+	if dir := p.config.ArchiveDir; dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return name, err
+		}
+		for i, file := range files {
+			target := filepath.Join(dir, filepath.Base(file))
+			if err := moveFile(file, target); err != nil {
+				return files[0], err
+			}
+			files[i] = target
+		}
+		name = files[0]
+	}
+	if p.config.Upload != "" {
+		store, ok := lookupObjectStore(p.config.Upload)
+		if !ok {
+			return name, fmt.Errorf("unknown object store %q", p.config.Upload)
+		}
+		for _, file := range files {
+			if err := upload(store, p.config.UploadPrefix+filepath.Base(file), file); err != nil {
+				return name, err
+			}
+		}
+	}
+	return name, nil
+}
+
+// upload 上传文件，失败时按UploadBackoff递增间隔重试
+func upload(store ObjectStore, key, name string) error {
+	var err error
+	for attempt := 0; attempt < max(UploadAttempts, 1); attempt++ {
+		if attempt > 0 {
+			time.Sleep(UploadBackoff * time.Duration(attempt))
+		}
+		if err = uploadOnce(store, key, name); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("upload %s: %w", key, err)
+}
+
+func uploadOnce(store ObjectStore, key, name string) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return store.Upload(context.Background(), key, file)
+}
+
+// writeChecksum 计算文件的sha256并写入同名.sha256文件，格式与sha256sum一致
+func writeChecksum(name string) (string, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	sum := name + ".sha256"
+	content := fmt.Sprintf("%x", hash.Sum(nil)) + "  " + filepath.Base(name) + "\n"
+	return sum, os.WriteFile(sum, []byte(content), 0o644)
+}
+
+// moveFile 移动文件，跨文件系统时复制后删除原文件
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return os.Remove(src)
+}
+
+// compressFile 将文件压缩为同名.gz或.zst文件并删除原文件，返回压缩后的文件名
+func compressFile(name, compression string) (string, error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return name, err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return name, err
+	}
+	target := name + compressedExts[compression]
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return name, err
+	}
+	if err := compressTo(dst, src, compression); err != nil {
+		dst.Close()
+		os.Remove(target)
+		return name, err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(target)
+		return name, err
+	}
+	// 保留原文件的修改时间，清理时按写入时间而不是压缩时间判断
+	os.Chtimes(target, info.ModTime(), info.ModTime())
+	return target, os.Remove(name)
+}
+
+func compressTo(dst io.Writer, src io.Reader, compression string) error {
+	var w io.WriteCloser
+	if compression == CompressionZstd {
+		enc, err := zstd.NewWriter(dst)
+		if err != nil {
+			return err
+		}
+		w = enc
+	} else {
+		w = gzip.NewWriter(dst)
+	}
+	if _, err := io.Copy(w, src); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// exists 判断切割出的文件是否存在，包括压缩后及移动到归档目录的文件
+func (p *postRotate) exists(name string) bool {
+	candidates := []string{name}
+	if p.config.ArchiveDir != "" {
+		candidates = append(candidates, filepath.Join(p.config.ArchiveDir, filepath.Base(name)))
+	}
+	for _, candidate := range candidates {
+		for _, ext := range []string{"", ".gz", ".zst"} {
+			if _, err := os.Stat(candidate + ext); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type backupFile struct {
+	path    string
+	modTime time.Time
+}
+
+// removeExpired 删除超过MaxAge天或超出MaxBackups个数的旧文件，归档目录中的文件同样计算在内，正在写入的文件不会被删除
+func (p *postRotate) removeExpired(active string) {
+	if p.config.MaxAge <= 0 && p.config.MaxBackups <= 0 {
+		return
+	}
+	dirs := []string{p.dir}
+	if p.config.ArchiveDir != "" && filepath.Clean(p.config.ArchiveDir) != filepath.Clean(p.dir) {
+		dirs = append(dirs, p.config.ArchiveDir)
+	}
+	var backups []backupFile
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+			if entry.IsDir() || path == active || !p.match.MatchString(entry.Name()) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			backups = append(backups, backupFile{path: path, modTime: info.ModTime()})
+		}
+	}
+	// 修改时间相同时按文件名排序，文件名中的日期及序号保证与时间顺序一致
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].modTime.Equal(backups[j].modTime) {
+			return backups[i].modTime.After(backups[j].modTime)
+		}
+		return backups[i].path > backups[j].path
+	})
+
+	if p.config.MaxBackups > 0 && len(backups) > p.config.MaxBackups {
+		for _, backup := range backups[p.config.MaxBackups:] {
+			removeBackup(backup.path)
+		}
+		backups = backups[:p.config.MaxBackups]
+	}
+	if p.config.MaxAge > 0 {
+		cutoff := p.now().Add(-time.Duration(p.config.MaxAge) * 24 * time.Hour)
+		for _, backup := range backups {
+			if backup.modTime.Before(cutoff) {
+				removeBackup(backup.path)
+			}
+		}
+	}
+}
+
+// removeBackup 删除旧文件及其校验和文件
+func removeBackup(name string) {
+	os.Remove(name)
+	os.Remove(name + ".sha256")
+}
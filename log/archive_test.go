@@ -0,0 +1,130 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+type memoryStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	fail    int
+}
+
+func (s *memoryStore) Upload(_ context.Context, key string, body io.Reader) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail > 0 {
+		s.fail--
+		return fmt.Errorf("temporary failure")
+	}
+	data, err := io.ReadAll(body)
+	s.objects[key] = data
+	return err
+}
+
+func TestPostRotate(t *testing.T) {
+	dir := t.TempDir()
+	archiveDir := filepath.Join(dir, "archive")
+	store := &memoryStore{objects: make(map[string][]byte), fail: 1}
+	if err := RegisterObjectStore("archive-test-store", store); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { UnregisterObjectStore("archive-test-store") })
+	backoff := UploadBackoff
+	UploadBackoff = time.Millisecond
+	defer func() { UploadBackoff = backoff }()
+
+	type rotation struct{ oldPath, newPath string }
+	rotations := make(chan rotation, 1)
+	RegisterRotateHook("archive-test", func(oldPath, newPath string) {
+		rotations <- rotation{oldPath, newPath}
+	})
+	defer RemoveRotateHook("archive-test")
+
+	r := newTimeRotator(FileLogConfig{
+		FileName:     filepath.Join(dir, "app.log"),
+		Rotation:     RotationDaily,
+		UTC:          true,
+		Compression:  CompressionZstd,
+		Checksum:     true,
+		ArchiveDir:   archiveDir,
+		Upload:       "archive-test-store",
+		UploadPrefix: "logs/",
+	})
+	r.now = func() time.Time { return time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC) }
+	defer r.Close()
+	r.Write([]byte("first day\n"))
+	if err := r.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := <-rotations
+	wantOld := filepath.Join(archiveDir, "app-2026-10-17.log.zst")
+	if got.oldPath != wantOld || got.newPath != filepath.Join(dir, "app-2026-10-17.1.log") {
+		t.Fatalf("unexpected hook paths %+v", got)
+	}
+	compressed, err := os.ReadFile(wantOld)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec, _ := zstd.NewReader(bytes.NewReader(compressed))
+	defer dec.Close()
+	if plain, err := io.ReadAll(dec); err != nil || string(plain) != "first day\n" {
+		t.Fatalf("zstd content %q (%v)", plain, err)
+	}
+	sum, _ := os.ReadFile(wantOld + ".sha256")
+	if want := fmt.Sprintf("%x  app-2026-10-17.log.zst\n", sha256.Sum256(compressed)); string(sum) != want {
+		t.Fatalf("checksum file %q, want %q", sum, want)
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if !bytes.Equal(store.objects["logs/app-2026-10-17.log.zst"], compressed) || store.objects["logs/app-2026-10-17.log.zst.sha256"] == nil {
+		t.Fatalf("upload should retry and store both files, got %d objects", len(store.objects))
+	}
+}
+
+func TestSizeRotatorHook(t *testing.T) {
+	dir := t.TempDir()
+	rotated := make(chan string, 1)
+	RegisterRotateHook("size-test", func(oldPath, _ string) { rotated <- oldPath })
+	defer RemoveRotateHook("size-test")
+
+	r := newSizeRotator(FileLogConfig{FileName: filepath.Join(dir, "app.log"), Compress: true, MaxBackups: 1})
+	r.maxSize = 10
+	defer r.Close()
+	r.Write([]byte("12345678\n"))
+	r.Write([]byte("abcdefgh\n"))
+
+	oldPath := <-rotated
+	if !strings.HasPrefix(filepath.Base(oldPath), "app-") || !strings.HasSuffix(oldPath, ".log.gz") {
+		t.Fatalf("unexpected rotated file %s", oldPath)
+	}
+	file, err := os.Open(oldPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain, _ := io.ReadAll(gz); string(plain) != "12345678\n" {
+		t.Fatalf("rotated content %q", plain)
+	}
+	if current, _ := os.ReadFile(filepath.Join(dir, "app.log")); string(current) != "abcdefgh\n" {
+		t.Fatalf("current content %q", current)
+	}
+}
@@ -0,0 +1,241 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// OverflowPolicy 异步缓冲区写满时的处理策略
+type OverflowPolicy string
+
+const (
+	// OverflowBlock 阻塞写入直到缓冲区有空位，不丢日志
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropNewest 丢弃当前写入的日志
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+	// OverflowDropOldest 丢弃缓冲区中最早的日志
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+)
+
+const (
+	defaultAsyncBufferSize    = 4096
+	defaultAsyncFlushInterval = time.Second
+)
+
+// AsyncConfig 异步写入配置，开启后日志先写入环形缓冲区，由后台协程批量写出；core类型的输出不受影响
+type AsyncConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled" mapstructure:"enabled"`
+	// BufferSize 缓冲区可容纳的日志条数，默认4096
+	BufferSize int `yaml:"buffer_size" json:"buffer_size" mapstructure:"buffer_size" validate:"min=0"`
+	// FlushInterval 后台写出间隔，默认1秒，缓冲区过半时提前写出
+	FlushInterval ConfigDuration `yaml:"flush_interval" json:"flush_interval" mapstructure:"flush_interval"`
+	// Overflow 缓冲区写满时的策略，block、drop_newest或drop_oldest，默认block
+	Overflow OverflowPolicy `yaml:"overflow" json:"overflow" mapstructure:"overflow" validate:"omitempty,oneof=block drop_newest drop_oldest"`
+}
+
+// asyncWriter 基于环形缓冲区的异步WriteSyncer，停止后退化为同步写入，持有旧core的协程仍可正常输出
+type asyncWriter struct {
+	ws      zapcore.WriteSyncer
+	policy  OverflowPolicy
+	counter *dropCounter
+
+	mu      sync.Mutex
+	notFull *sync.Cond
+	ring    [][]byte
+	head    int
+	size    int
+	stopped bool
+
+	// flushMu 保证批量写出与停止后的直接写入按顺序进行
+	flushMu sync.Mutex
+	batch   []byte
+
+	wake chan struct{}
+	quit chan struct{}
+	done chan struct{}
+}
+
+func newAsyncWriter(ws zapcore.WriteSyncer, config AsyncConfig, counter *dropCounter) *asyncWriter {
+	size := config.BufferSize
+	if size <= 0 {
+		size = defaultAsyncBufferSize
+	}
+	interval := time.Duration(config.FlushInterval)
+	if interval <= 0 {
+		interval = defaultAsyncFlushInterval
+	}
+	w := &asyncWriter{
+		ws:      ws,
+		policy:  config.Overflow,
+		counter: counter,
+		ring:    make([][]byte, size),
+		wake:    make(chan struct{}, 1),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	w.notFull = sync.NewCond(&w.mu)
+	go w.run(interval)
+	return w
+}
+
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	// zap会复用p，缓冲前需要复制
+	entry := append([]byte(nil), p...)
+
+	w.mu.Lock()
+	for w.size == len(w.ring) && !w.stopped {
+		switch w.policy {
+		case OverflowDropNewest:
+			w.mu.Unlock()
+			w.counter.overflowed.Add(1)
+			return len(p), nil
+		case OverflowDropOldest:
+			w.ring[w.head] = nil
+			w.head = (w.head + 1) % len(w.ring)
+			w.size--
+			w.counter.overflowed.Add(1)
+		default:
+			w.signal()
+			w.notFull.Wait()
+		}
+	}
+	if w.stopped {
+		w.mu.Unlock()
+		w.flushMu.Lock()
+		defer w.flushMu.Unlock()
+		return w.ws.Write(p)
+	}
+	w.ring[(w.head+w.size)%len(w.ring)] = entry
+	w.size++
+	if w.size >= len(w.ring)/2 {
+		w.signal()
+	}
+	w.mu.Unlock()
+	return len(p), nil
+}
+
+// Sync 写出缓冲区中的全部日志并同步底层输出
+func (w *asyncWriter) Sync() error {
+	return errors.Join(w.flush(), w.ws.Sync())
+}
+
+func (w *asyncWriter) signal() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *asyncWriter) run(interval time.Duration) {
+	defer close(w.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.wake:
+		case <-w.quit:
+			w.report(w.flush())
+			return
+		}
+		w.report(w.flush())
+	}
+}
+
+// flush 取出缓冲区中的日志合并为一次写入
+func (w *asyncWriter) flush() error {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	w.mu.Lock()
+	w.batch = w.batch[:0]
+	for ; w.size > 0; w.size-- {
+		w.batch = append(w.batch, w.ring[w.head]...)
+		w.ring[w.head] = nil
+		w.head = (w.head + 1) % len(w.ring)
+	}
+	w.notFull.Broadcast()
+	w.mu.Unlock()
+
+	if len(w.batch) == 0 {
+		return nil
+	}
+	_, err := w.ws.Write(w.batch)
+	return err
+}
+
+func (w *asyncWriter) report(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v log: async write failed: %v\n", time.Now(), err)
+	}
+}
+
+// stop 停止后台协程并写出剩余日志，此后的写入直接同步写出
+func (w *asyncWriter) stop() {
+	w.mu.Lock()
+	if w.stopped {
+		w.mu.Unlock()
+		return
+	}
+	w.stopped = true
+	w.notFull.Broadcast()
+	w.mu.Unlock()
+	close(w.quit)
+	<-w.done
+}
+
+// Sync 同步全局及所有子logger的输出，异步模式下会先写出缓冲区中的日志
+func Sync() error {
+	logLock.RLock()
+	cores := allCores()
+	logLock.RUnlock()
+	return syncAll(cores)
+}
+
+// allCores 返回全部logger当前的core，调用方需持有logLock；同步等可能阻塞的操作应在释放logLock后进行
+func allCores() []zapcore.Core {
+	cores := make([]zapcore.Core, 0, len(childStates)+1)
+	cores = append(cores, globalState.core.Load())
+	for _, state := range childStates {
+		cores = append(cores, state.core.Load())
+	}
+	return cores
+}
+
+// syncAll 同步全部core
+func syncAll(cores []zapcore.Core) error {
+	var errs []error
+	for _, core := range cores {
+		errs = append(errs, core.Sync())
+	}
+	return errors.Join(errs...)
+}
+
+// Shutdown 写出全部缓冲日志，停止异步写入协程并关闭日志文件，应在进程退出前调用；
+// ctx到期时立即返回ctx.Err()，剩余工作在后台继续完成。Shutdown之后的日志同步写入，日志文件在写入时重新打开
+func Shutdown(ctx context.Context) error {
+	result := make(chan error, 1)
+	go func() {
+		// 持有logLock时仅取出待处理的输出，阻塞的输出不会影响其他logger
+		logLock.Lock()
+		retired := &retiredSinks{cores: allCores(), async: asyncWriters, limiters: rateLimiters}
+		asyncWriters, rateLimiters = nil, nil
+		for _, hook := range files {
+			retired.files = append(retired.files, hook)
+		}
+		logLock.Unlock()
+		result <- retired.release()
+	}()
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
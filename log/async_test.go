@@ -0,0 +1,173 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type blockingWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *blockingWriter) Sync() error {
+	return nil
+}
+
+func (w *blockingWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAsyncWriterOverflow(t *testing.T) {
+	for _, tt := range []struct {
+		policy OverflowPolicy
+		want   string
+	}{
+		{OverflowDropNewest, "0\n1\n"},
+		{OverflowDropOldest, "2\n3\n"},
+	} {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			var counter dropCounter
+			ws := &blockingWriter{release: make(chan struct{})}
+			close(ws.release)
+			w := newAsyncWriter(ws, AsyncConfig{BufferSize: 2, FlushInterval: ConfigDuration(time.Hour), Overflow: tt.policy}, &counter)
+			defer w.stop()
+			// 缓冲区过半会唤醒后台协程，这里直接占满缓冲区避免竞争
+			w.mu.Lock()
+			w.ring[0], w.ring[1], w.size = []byte("0\n"), []byte("1\n"), 2
+			w.mu.Unlock()
+			w.Write([]byte("2\n"))
+			w.Write([]byte("3\n"))
+			if err := w.Sync(); err != nil {
+				t.Fatal(err)
+			}
+			if ws.String() != tt.want || counter.overflowed.Load() != 2 {
+				t.Fatalf("got %q with %d dropped", ws.String(), counter.overflowed.Load())
+			}
+		})
+	}
+}
+
+func TestAsyncWriterBlock(t *testing.T) {
+	var counter dropCounter
+	ws := &blockingWriter{release: make(chan struct{})}
+	w := newAsyncWriter(ws, AsyncConfig{BufferSize: 2, FlushInterval: ConfigDuration(time.Hour)}, &counter)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			w.Write([]byte{'a' + byte(i), '\n'})
+		}
+	}()
+	select {
+	case <-done:
+		t.Fatal("writes should block while the buffer is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(ws.release)
+	<-done
+	w.stop()
+	if got := ws.String(); got != "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n" || counter.overflowed.Load() != 0 {
+		t.Fatalf("block policy should keep every entry in order, got %q", got)
+	}
+	// 停止后同步写入
+	w.Write([]byte("k\n"))
+	if !strings.HasSuffix(ws.String(), "k\n") {
+		t.Fatal("writes after stop should go straight through")
+	}
+}
+
+func TestAsyncLogger(t *testing.T) {
+	resetLoggers(t)
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "async.log")
+	err := InitLoggerE(GlobalConfig{Level: "info"}, ChildConfig{
+		LoggerName: "async",
+		Level:      "info",
+		Sinks:      []SinkConfig{{Type: SinkFile, FileLogConfig: FileLogConfig{FileName: fileName}}},
+		ConfigBase: ConfigBase{Async: AsyncConfig{Enabled: true, FlushInterval: ConfigDuration(time.Hour)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	GetLoggerWithFileName("async").Info("buffered entry")
+	if content, _ := os.ReadFile(fileName); strings.Contains(string(content), "buffered entry") {
+		t.Fatal("entry should stay buffered until flushed")
+	}
+	if err := Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(fileName); !strings.Contains(string(content), "buffered entry") {
+		t.Fatalf("Sync should flush buffered entries, got %q", content)
+	}
+
+	GetLoggerWithFileName("async").Info("before shutdown")
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(fileName); !strings.Contains(string(content), "before shutdown") {
+		t.Fatalf("Shutdown should flush buffered entries, got %q", content)
+	}
+	GetLoggerWithFileName("async").Info("after shutdown")
+	if content, _ := os.ReadFile(fileName); !strings.Contains(string(content), "after shutdown") {
+		t.Fatalf("logging after Shutdown should write synchronously, got %q", content)
+	}
+}
+
+func TestShutdownContext(t *testing.T) {
+	resetLoggers(t)
+	ws := &blockingWriter{release: make(chan struct{})}
+	registerTestSink(t, "async-test-blocking", ws)
+	InitLogger(GlobalConfig{Level: "info"}, ChildConfig{
+		LoggerName: "slow",
+		Level:      "info",
+		Sinks:      []SinkConfig{{Type: "async-test-blocking"}},
+		ConfigBase: ConfigBase{Async: AsyncConfig{Enabled: true, FlushInterval: ConfigDuration(time.Hour)}},
+	})
+	GetLoggerWithFileName("slow").Info("stuck")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("want deadline exceeded, got %v", err)
+	}
+	// 阻塞的输出不应影响其他logger的获取、输出及配置
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		GetLogger().Info("not blocked")
+		AddChildLogger(ChildConfig{LoggerName: "fast", Sinks: []SinkConfig{{Type: SinkDiscard}}})
+		Get("fast").Info("not blocked")
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("logging should not block while Shutdown waits for a blocked sink")
+	}
+	close(ws.release)
+	// 等待后台的Shutdown完成，避免影响后续测试
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(ws.String(), "stuck") {
+		if time.Now().After(deadline) {
+			t.Fatal("shutdown should finish in the background")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,48 @@
+package log
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// Level 日志级别
+type Level = zapcore.Level
+
+// 日志级别
+const (
+	DebugLevel  = zapcore.DebugLevel
+	InfoLevel   = zapcore.InfoLevel
+	WarnLevel   = zapcore.WarnLevel
+	ErrorLevel  = zapcore.ErrorLevel
+	DPanicLevel = zapcore.DPanicLevel
+	PanicLevel  = zapcore.PanicLevel
+	FatalLevel  = zapcore.FatalLevel
+)
+
+// CheckedEntry Check返回的待输出日志，调用Write时输出
+type CheckedEntry = zapcore.CheckedEntry
+
+// Enabled 全局logger是否输出level级别的日志
+func Enabled(level Level) bool {
+	return GetLogger().Enabled(level)
+}
+
+// Check 检查全局logger是否输出该日志，返回nil时表示不输出，用法见(*Logger).Check
+func Check(level Level, msg string) *CheckedEntry {
+	return GetLogger().zapLogger().Check(level, msg)
+}
+
+// Enabled 是否有输出接收level级别的日志，用于跳过构建开销较大的字段
+func (log *Logger) Enabled(level Level) bool {
+	return log.l.Core().Enabled(level)
+}
+
+// Check 检查是否输出该日志，返回nil时表示不输出，否则调用Write时才构建字段并输出，如
+//
+//	if ce := logger.Check(log.DebugLevel, "payload"); ce != nil {
+//		ce.Write(log.String("body", dump(payload)))
+//	}
+//
+// 与采样、限流配合时Check同时计入统计，返回的日志需调用Write
+func (log *Logger) Check(level Level, msg string) *CheckedEntry {
+	return log.zapLogger().Check(level, msg)
+}
@@ -0,0 +1,50 @@
+package log
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckAndLazy(t *testing.T) {
+	resetLoggers(t)
+	first, second := &lockedBuffer{}, &lockedBuffer{}
+	registerTestSink(t, "check-test-first", first)
+	registerTestSink(t, "check-test-second", second)
+	sinks := []SinkConfig{{Type: "check-test-first", Format: FormatJSON}, {Type: "check-test-second", Format: FormatJSON}}
+	InitLogger(GlobalConfig{Level: "info", ConfigBase: ConfigBase{ShowLineNumber: true}, Sinks: sinks},
+		ChildConfig{LoggerName: "payments", Level: "warn", Inherit: true})
+
+	payments := Get("payments")
+	if !Enabled(InfoLevel) || Enabled(DebugLevel) || payments.Enabled(InfoLevel) || !payments.Enabled(ErrorLevel) {
+		t.Fatal("Enabled should follow the logger level")
+	}
+	if ce := payments.Check(InfoLevel, "skipped"); ce != nil {
+		t.Fatal("Check should return nil for a disabled level")
+	}
+
+	calls := 0
+	payload := Lazy(func() Field {
+		calls++
+		return String("payload", "expensive")
+	})
+	payments.Info("disabled", payload)
+	if calls != 0 {
+		t.Fatal("lazy field must not be evaluated for a disabled level")
+	}
+	if ce := payments.Check(WarnLevel, "checked"); ce != nil {
+		ce.Write(payload, LazyObject("user", func() ObjectMarshaler {
+			return testUser{Name: "alice", Tags: []string{"vip"}}
+		}))
+	}
+	if calls != 1 {
+		t.Fatalf("lazy field should be evaluated once for both sinks, got %d", calls)
+	}
+	for _, buf := range []*lockedBuffer{first, second} {
+		lines := buf.lines()
+		if len(lines) != 1 || !strings.Contains(lines[0], `"payload":"expensive"`) ||
+			!strings.Contains(lines[0], `"user":{"name":"alice","tags":["vip"]}`) ||
+			!strings.Contains(lines[0], `"caller":"log/check_test.go:`) {
+			t.Fatalf("unexpected output: %q", lines)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestReplaceAndRemoveChildLogger(t *testing.T) {
+	resetLoggers(t)
+	dir := t.TempDir()
+	oldFile, newFile := filepath.Join(dir, "old.log"), filepath.Join(dir, "new.log")
+	err := InitLoggerE(GlobalConfig{Level: "info", Sinks: []SinkConfig{{Type: SinkDiscard}}}, ChildConfig{
+		LoggerName: "orders",
+		Level:      "info",
+		Outputs:    []OutputConfig{{FileLogConfig: FileLogConfig{FileName: oldFile}}},
+		ConfigBase: ConfigBase{Async: AsyncConfig{Enabled: true}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	orders := GetLoggerWithFileName("orders")
+
+	// 替换及移除期间持有旧Logger的协程持续写入
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					orders.Info("concurrent")
+				}
+			}
+		}()
+	}
+
+	orders.Info("before replace")
+	err = ReplaceChildLogger(ChildConfig{
+		LoggerName: "orders",
+		Level:      "info",
+		Outputs:    []OutputConfig{{FileLogConfig: FileLogConfig{FileName: newFile}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	orders.Info("after replace")
+	if _, ok := files[oldFile]; ok {
+		t.Fatal("old file should be closed and released")
+	}
+	if configs := ListChildLoggers(); len(configs) != 1 || configs[0].Outputs[0].FileName != newFile {
+		t.Fatalf("unexpected child configs %+v", configs)
+	}
+	if err := ReplaceChildLogger(ChildConfig{LoggerName: "missing"}); err == nil {
+		t.Fatal("replacing an unknown logger should fail")
+	}
+
+	if err := RemoveChildLogger("orders"); err != nil {
+		t.Fatal(err)
+	}
+	orders.Info("after remove")
+	close(stop)
+	wg.Wait()
+
+	oldContent, _ := os.ReadFile(oldFile)
+	newContent, _ := os.ReadFile(newFile)
+	if !strings.Contains(string(oldContent), "before replace") || strings.Contains(string(oldContent), "after replace") {
+		t.Fatalf("old file got %q", oldContent)
+	}
+	if !strings.Contains(string(newContent), "after replace") || strings.Contains(string(newContent), "after remove") {
+		t.Fatal("new file should receive entries until the logger is removed")
+	}
+	if GetLoggerWithFileName("orders") != nil || len(ListChildLoggers()) != 0 {
+		t.Fatal("removed logger should no longer be registered")
+	}
+	if _, ok := files[newFile]; ok {
+		t.Fatal("files of removed loggers should be closed")
+	}
+	if err := RemoveChildLogger("orders"); err == nil {
+		t.Fatal("removing twice should fail")
+	}
+}
+
+// failingSyncer Sync始终失败的输出
+type failingSyncer struct {
+	io.Writer
+}
+
+func (failingSyncer) Sync() error {
+	return errors.New("sync failed")
+}
+
+func TestReleaseErrors(t *testing.T) {
+	resetLoggers(t)
+	registerTestSink(t, "child-test-failing", failingSyncer{io.Discard})
+	sinks := []SinkConfig{{Type: "child-test-failing"}}
+	if err := InitLoggerE(GlobalConfig{Level: "info"}, ChildConfig{LoggerName: "orders", Level: "info", Sinks: sinks}); err != nil {
+		t.Fatal(err)
+	}
+	err := ReplaceChildLogger(ChildConfig{LoggerName: "orders", Level: "warn", Sinks: sinks})
+	if err == nil || !strings.Contains(err.Error(), "sync failed") {
+		t.Fatalf("replace should report the failed sync of the old sink, got %v", err)
+	}
+	if level, _ := GetLevel("orders"); level != "warn" {
+		t.Fatalf("replacement should still be applied, got %s", level)
+	}
+	if err := RemoveChildLogger("orders"); err == nil || !strings.Contains(err.Error(), "sync failed") {
+		t.Fatalf("remove should report the failed sync of the old sink, got %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix 环境变量覆盖配置时使用的前缀，全局配置为LOG_<KEY>，子logger为LOG_<CHILD>_<KEY>
+const EnvPrefix = "LOG_"
+
+// FileConfig 日志配置文件结构，包含全局logger配置及子logger配置列表
+type FileConfig struct {
+	Global   GlobalConfig  `yaml:"global" json:"global" mapstructure:"global"`
+	Children []ChildConfig `yaml:"children" json:"children" mapstructure:"children" validate:"dive"`
+}
+
+// LoadConfig 读取并校验日志配置，format支持yaml、json、toml，读取后使用环境变量覆盖对应字段
+//
+// 环境变量的KEY为字段json标签的大写形式，如LOG_LEVEL、LOG_FILE_NAME，嵌套字段使用下划线连接，如LOG_ENCODER_TIME_KEY；
+// 子logger名称转为大写且非字母数字字符替换为下划线，如kafka.consumer对应LOG_KAFKA_CONSUMER_LEVEL
+func LoadConfig(r io.Reader, format string) (FileConfig, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return FileConfig{}, fmt.Errorf("log: read config: %w", err)
+	}
+	config, err := decodeFileConfig(content, format)
+	if err != nil {
+		return config, err
+	}
+	if err := applyEnvOverrides(&config, os.LookupEnv); err != nil {
+		return config, err
+	}
+	return config, validateConfig(config, nil)
+}
+
+// decodeFileConfig 解析配置文件内容，yaml、toml先转换为通用结构再按json标签解码，未知字段视为错误
+func decodeFileConfig(content []byte, format string) (FileConfig, error) {
+	var config FileConfig
+	var raw map[string]interface{}
+	switch strings.ToLower(format) {
+	case "json":
+		if err := decodeJSON(content, &config); err != nil {
+			return config, fmt.Errorf("log: decode json config: %w", err)
+		}
+		return config, nil
+	case "yaml", "yml":
+		if err := yaml.Unmarshal(content, &raw); err != nil {
+			return config, fmt.Errorf("log: decode yaml config: %w", err)
+		}
+	case "toml":
+		if _, err := toml.Decode(string(content), &raw); err != nil {
+			return config, fmt.Errorf("log: decode toml config: %w", err)
+		}
+	default:
+		return config, fmt.Errorf("log: unsupported config format %q", format)
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return config, fmt.Errorf("log: decode %s config: %w", format, err)
+	}
+	if err := decodeJSON(data, &config); err != nil {
+		return config, fmt.Errorf("log: decode %s config: %w", format, err)
+	}
+	return config, nil
+}
+
+func decodeJSON(data []byte, v interface{}) error {
+	if len(bytes.TrimSpace(data)) == 0 || string(bytes.TrimSpace(data)) == "null" {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// applyEnvOverrides 使用环境变量覆盖配置字段
+func applyEnvOverrides(config *FileConfig, lookup func(string) (string, bool)) error {
+	var errs []error
+	errs = append(errs, overrideFields(reflect.ValueOf(&config.Global).Elem(), EnvPrefix, lookup)...)
+	for i := range config.Children {
+		prefix := EnvPrefix + envName(config.Children[i].LoggerName) + "_"
+		errs = append(errs, overrideFields(reflect.ValueOf(&config.Children[i]).Elem(), prefix, lookup)...)
+	}
+	return errors.Join(errs...)
+}
+
+// overrideFields 按json标签遍历结构体字段，匿名嵌入的结构体字段平铺处理
+func overrideFields(v reflect.Value, prefix string, lookup func(string) (string, bool)) []error {
+	var errs []error
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			errs = append(errs, overrideFields(v.Field(i), prefix, lookup)...)
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" || name == "logger_name" || field.Type.Kind() == reflect.Slice {
+			continue
+		}
+		key := prefix + strings.ToUpper(name)
+		if field.Type.Kind() == reflect.Struct {
+			errs = append(errs, overrideFields(v.Field(i), key+"_", lookup)...)
+			continue
+		}
+		value, ok := lookup(key)
+		if !ok {
+			continue
+		}
+		if err := setField(v.Field(i), value); err != nil {
+			errs = append(errs, fmt.Errorf("log: env %s: %w", key, err))
+		}
+	}
+	return errs
+}
+
+func setField(v reflect.Value, value string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid bool %q", value)
+		}
+		v.SetBool(b)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		v.SetFloat(f)
+	case reflect.Int64:
+		if v.Type() != reflect.TypeOf(ConfigDuration(0)) {
+			return setInt(v, value)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		v.SetInt(int64(d))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return setInt(v, value)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
+
+func setInt(v reflect.Value, value string) error {
+	n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+	if err != nil {
+		return fmt.Errorf("invalid integer %q", value)
+	}
+	v.SetInt(n)
+	return nil
+}
+
+// envName 将logger名称转换为环境变量片段
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,103 @@
+package log
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "warn")
+	t.Setenv("LOG_KAFKA_CONSUMER_MAX_SIZE", "64")
+	t.Setenv("LOG_KAFKA_CONSUMER_ENABLE_FILE_LOG", "true")
+
+	config, err := LoadConfig(strings.NewReader(`
+[global]
+level = "debug"
+json_format = true
+
+[[children]]
+logger_name = "kafka.consumer"
+level = "error"
+file_name = "logs/kafka.log"
+`), "toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Global.Level != "warn" || !config.Global.JSONFormat {
+		t.Fatalf("unexpected global config %+v", config.Global)
+	}
+	child := config.Children[0]
+	if child.LoggerName != "kafka.consumer" || child.Level != "error" || child.MaxSize != 64 || !child.EnableFileLog {
+		t.Fatalf("unexpected child config %+v", child)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	_, err := LoadConfig(strings.NewReader(`{
+		"global": {"level": "warning", "max_size": -1},
+		"children": [{"level": "INFO"}]
+	}`), "json")
+	if err == nil {
+		t.Fatal("invalid config should fail")
+	}
+	for _, want := range []string{
+		`global.level: unknown level "warning"`,
+		`global.max_size: must be >= 0, got -1`,
+		`children[0].logger_name: is required`,
+		`children[0].level: unknown level "INFO"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q should contain %q", err, want)
+		}
+	}
+
+	t.Setenv("LOG_MAX_AGE", "seven")
+	if _, err := LoadConfig(strings.NewReader(`global: {level: info}`), "yaml"); err == nil || !strings.Contains(err.Error(), "LOG_MAX_AGE") {
+		t.Fatalf("invalid env override should fail, got %v", err)
+	}
+	if _, err := LoadConfig(strings.NewReader(`{"global": {"levle": "info"}}`), "json"); err == nil {
+		t.Fatal("unknown field should fail")
+	}
+	if _, err := LoadConfig(strings.NewReader(``), "ini"); err == nil {
+		t.Fatal("unsupported format should fail")
+	}
+}
+
+func TestInitLoggerE(t *testing.T) {
+	resetLoggers(t)
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := InitLoggerE(GlobalConfig{Level: "warning", EnableFileLog: true},
+		ChildConfig{LoggerName: "kafka", FileLogConfig: FileLogConfig{MaxSize: -1}},
+		ChildConfig{LoggerName: "kafka", EnableFileLog: true, FileLogConfig: FileLogConfig{FileName: filepath.Join(blocker, "kafka.log")}},
+	)
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) {
+		t.Fatalf("want *ConfigError, got %v", err)
+	}
+	got := make(map[string]string)
+	for _, fe := range configErr.Errors {
+		got[fe.Path] = fe.Reason
+	}
+	for _, path := range []string{"global.level", "global.file_name", "children[0].max_size", "children[1].logger_name", "children[1].file_name"} {
+		if _, ok := got[path]; !ok {
+			t.Errorf("missing error for %s in %v", path, err)
+		}
+	}
+
+	if err := InitLoggerE(GlobalConfig{Level: "info"}, ChildConfig{LoggerName: "kafka"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddChildLoggerE(ChildConfig{LoggerName: "kafka"}); !errors.As(err, &configErr) {
+		t.Fatalf("duplicate child logger should fail, got %v", err)
+	}
+	if err := AddChildLoggerE(ChildConfig{LoggerName: "redis", Level: "debug"}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,216 @@
+package log
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// 内置上下文字段输出的key
+const (
+	TraceIDKey   = "trace_id"
+	SpanIDKey    = "span_id"
+	RequestIDKey = "request_id"
+	UserIDKey    = "user_id"
+	TenantKey    = "tenant"
+)
+
+// ContextExtractor 从context中提取字段并追加到fields后返回，未找到相关信息时原样返回fields
+type ContextExtractor func(ctx context.Context, fields []Field) []Field
+
+type namedExtractor struct {
+	name      string
+	extractor ContextExtractor
+}
+
+var (
+	extractorLock sync.Mutex
+	extractors    atomic.Pointer[[]namedExtractor]
+)
+
+func init() {
+	RegisterContextExtractor("builtin", builtinExtractor)
+}
+
+// RegisterContextExtractor 注册context字段提取器，按注册顺序执行，同名提取器会被替换
+func RegisterContextExtractor(name string, extractor ContextExtractor) {
+	extractorLock.Lock()
+	defer extractorLock.Unlock()
+	var current []namedExtractor
+	if p := extractors.Load(); p != nil {
+		current = *p
+	}
+	next := make([]namedExtractor, 0, len(current)+1)
+	replaced := false
+	for _, e := range current {
+		if e.name == name {
+			e.extractor = extractor
+			replaced = true
+		}
+		next = append(next, e)
+	}
+	if !replaced {
+		next = append(next, namedExtractor{name: name, extractor: extractor})
+	}
+	extractors.Store(&next)
+}
+
+// RemoveContextExtractor 移除context字段提取器，内置提取器名称为builtin
+func RemoveContextExtractor(name string) {
+	extractorLock.Lock()
+	defer extractorLock.Unlock()
+	p := extractors.Load()
+	if p == nil {
+		return
+	}
+	next := make([]namedExtractor, 0, len(*p))
+	for _, e := range *p {
+		if e.name != name {
+			next = append(next, e)
+		}
+	}
+	extractors.Store(&next)
+}
+
+// contextFields 依次执行已注册的提取器，返回的切片不会修改调用方传入的fields
+func contextFields(ctx context.Context, fields []Field) []Field {
+	p := extractors.Load()
+	if ctx == nil || p == nil || len(*p) == 0 {
+		return fields
+	}
+	merged := make([]Field, len(fields), len(fields)+4)
+	copy(merged, fields)
+	for _, e := range *p {
+		merged = e.extractor(ctx, merged)
+	}
+	return merged
+}
+
+type contextFieldKey string
+
+// ContextWithTraceID 在context中记录trace id，使用*Ctx方法输出日志时自动附加trace_id字段
+func ContextWithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, contextFieldKey(TraceIDKey), traceID)
+}
+
+// ContextWithSpanID 在context中记录span id
+func ContextWithSpanID(ctx context.Context, spanID string) context.Context {
+	return context.WithValue(ctx, contextFieldKey(SpanIDKey), spanID)
+}
+
+// ContextWithRequestID 在context中记录request id
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, contextFieldKey(RequestIDKey), requestID)
+}
+
+// ContextWithUserID 在context中记录用户id
+func ContextWithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, contextFieldKey(UserIDKey), userID)
+}
+
+// ContextWithTenant 在context中记录租户
+func ContextWithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, contextFieldKey(TenantKey), tenant)
+}
+
+var builtinKeys = []string{TraceIDKey, SpanIDKey, RequestIDKey, UserIDKey, TenantKey}
+
+// builtinExtractor 提取通过ContextWithXxx写入的字段
+func builtinExtractor(ctx context.Context, fields []Field) []Field {
+	for _, key := range builtinKeys {
+		if value, ok := ctx.Value(contextFieldKey(key)).(string); ok && value != "" {
+			fields = append(fields, String(key, value))
+		}
+	}
+	return fields
+}
+
+// 包级别函数直接调用Check，与Logger的方法调用栈深度相同，保证调用位置指向调用方
+
+func DebugCtx(ctx context.Context, msg string, fields ...Field) {
+	if ce := GetContextLogger(ctx).zapLogger().Check(zapcore.DebugLevel, msg); ce != nil {
+		ce.Write(contextFields(ctx, fields)...)
+	}
+}
+
+func InfoCtx(ctx context.Context, msg string, fields ...Field) {
+	if ce := GetContextLogger(ctx).zapLogger().Check(zapcore.InfoLevel, msg); ce != nil {
+		ce.Write(contextFields(ctx, fields)...)
+	}
+}
+
+func WarnCtx(ctx context.Context, msg string, fields ...Field) {
+	if ce := GetContextLogger(ctx).zapLogger().Check(zapcore.WarnLevel, msg); ce != nil {
+		ce.Write(contextFields(ctx, fields)...)
+	}
+}
+
+func ErrorCtx(ctx context.Context, msg string, fields ...Field) {
+	if ce := GetContextLogger(ctx).zapLogger().Check(zapcore.ErrorLevel, msg); ce != nil {
+		ce.Write(contextFields(ctx, fields)...)
+	}
+}
+
+func DPanicCtx(ctx context.Context, msg string, fields ...Field) {
+	if ce := GetContextLogger(ctx).zapLogger().Check(zapcore.DPanicLevel, msg); ce != nil {
+		ce.Write(contextFields(ctx, fields)...)
+	}
+}
+
+func PanicCtx(ctx context.Context, msg string, fields ...Field) {
+	if ce := GetContextLogger(ctx).zapLogger().Check(zapcore.PanicLevel, msg); ce != nil {
+		ce.Write(contextFields(ctx, fields)...)
+	}
+}
+
+func FatalCtx(ctx context.Context, msg string, fields ...Field) {
+	if ce := GetContextLogger(ctx).zapLogger().Check(zapcore.FatalLevel, msg); ce != nil {
+		ce.Write(contextFields(ctx, fields)...)
+	}
+}
+
+// 以下方法仅在对应级别开启时才执行context字段提取
+
+func (log *Logger) DebugCtx(ctx context.Context, msg string, fields ...Field) {
+	if ce := log.zapLogger().Check(zapcore.DebugLevel, msg); ce != nil {
+		ce.Write(contextFields(ctx, fields)...)
+	}
+}
+
+func (log *Logger) InfoCtx(ctx context.Context, msg string, fields ...Field) {
+	if ce := log.zapLogger().Check(zapcore.InfoLevel, msg); ce != nil {
+		ce.Write(contextFields(ctx, fields)...)
+	}
+}
+
+func (log *Logger) WarnCtx(ctx context.Context, msg string, fields ...Field) {
+	if ce := log.zapLogger().Check(zapcore.WarnLevel, msg); ce != nil {
+		ce.Write(contextFields(ctx, fields)...)
+	}
+}
+
+func (log *Logger) ErrorCtx(ctx context.Context, msg string, fields ...Field) {
+	if ce := log.zapLogger().Check(zapcore.ErrorLevel, msg); ce != nil {
+		ce.Write(contextFields(ctx, fields)...)
+	}
+}
+
+func (log *Logger) DPanicCtx(ctx context.Context, msg string, fields ...Field) {
+	if ce := log.zapLogger().Check(zapcore.DPanicLevel, msg); ce != nil {
+		ce.Write(contextFields(ctx, fields)...)
+	}
+}
+
+func (log *Logger) PanicCtx(ctx context.Context, msg string, fields ...Field) {
+	if ce := log.zapLogger().Check(zapcore.PanicLevel, msg); ce != nil {
+		ce.Write(contextFields(ctx, fields)...)
+	}
+}
+
+func (log *Logger) FatalCtx(ctx context.Context, msg string, fields ...Field) {
+	if ce := log.zapLogger().Check(zapcore.FatalLevel, msg); ce != nil {
+		ce.Write(contextFields(ctx, fields)...)
+	}
+}
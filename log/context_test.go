@@ -0,0 +1,66 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestContextFields(t *testing.T) {
+	resetLoggers(t)
+	var buf bytes.Buffer
+	registerTestSink(t, "context-test-buffer", zapcore.AddSync(&buf))
+	err := InitLoggerE(GlobalConfig{Level: "info"}, ChildConfig{
+		LoggerName: "api",
+		Level:      "info",
+		ConfigBase: ConfigBase{Format: FormatJSON},
+		Sinks:      []SinkConfig{{Type: "context-test-buffer"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type sessionKey struct{}
+	RegisterContextExtractor("session", func(ctx context.Context, fields []Field) []Field {
+		if session, ok := ctx.Value(sessionKey{}).(string); ok {
+			fields = append(fields, String("session", session))
+		}
+		return fields
+	})
+	defer RemoveContextExtractor("session")
+
+	ctx := ContextWithTraceID(context.Background(), "trace-1")
+	ctx = ContextWithRequestID(ctx, "req-1")
+	ctx = ContextWithTenant(ctx, "acme")
+	ctx = context.WithValue(ctx, sessionKey{}, "s-1")
+	ctx = SetContextLogger(ctx, GetLoggerWithFileName("api"))
+
+	fields := []Field{String("path", "/orders")}
+	InfoCtx(ctx, "handled", fields...)
+	GetLoggerWithFileName("api").DebugCtx(ctx, "filtered")
+	NewSlog("api").InfoContext(ctx, "from slog")
+
+	if len(fields) != 1 {
+		t.Fatal("caller fields must not be modified")
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("want 2 lines, got %q", buf.String())
+	}
+	for _, line := range lines {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+		if entry["trace_id"] != "trace-1" || entry["request_id"] != "req-1" || entry["tenant"] != "acme" || entry["session"] != "s-1" {
+			t.Fatalf("context fields missing in %v", entry)
+		}
+		if _, ok := entry["span_id"]; ok {
+			t.Fatalf("unset span id should be omitted, got %v", entry)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package log
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// loggerState 单个logger的运行时状态，配置变更时原地更新，已分发的Logger无需重新获取
+type loggerState struct {
+	level   zap.AtomicLevel
+	core    coreRef
+	dropped dropCounter
+	// caller 是否记录调用位置，未开启ShowLineNumber时跳过runtime.Caller的开销
+	caller atomic.Bool
+	// levelSource 最近一次按配置或级别规则设置的级别，读写需持有logLock
+	levelSource *levelSource
+}
+
+// applyLevel 按配置或级别规则设置级别，调用方需持有logLock
+func (s *loggerState) applyLevel(source levelSource) {
+	s.levelSource = &source
+	s.level.SetLevel(source.level)
+}
+
+func newLoggerState() *loggerState {
+	state := &loggerState{level: zap.NewAtomicLevel()}
+	state.core.Store(zapcore.NewNopCore())
+	state.caller.Store(true)
+	return state
+}
+
+// newLogger 创建绑定该状态的Logger，按状态中的caller决定是否记录调用位置
+func (s *loggerState) newLogger() *Logger {
+	return wrapLogger(zap.New(&swapCore{ref: &s.core}, zap.AddCaller(), zap.AddCallerSkip(1)), &s.caller)
+}
+
+type coreBox struct {
+	zapcore.Core
+}
+
+// coreRef 可原子替换的core引用
+type coreRef struct {
+	p atomic.Pointer[coreBox]
+}
+
+func (r *coreRef) Load() *coreBox {
+	return r.p.Load()
+}
+
+func (r *coreRef) Store(core zapcore.Core) {
+	r.p.Store(&coreBox{core})
+}
+
+type derivedCore struct {
+	base *coreBox
+	core zapcore.Core
+}
+
+// swapCore 委托给coreRef当前指向的core，With添加的字段在底层core替换后重新附加
+type swapCore struct {
+	ref    *coreRef
+	fields []zapcore.Field
+	cache  atomic.Pointer[derivedCore]
+}
+
+func (c *swapCore) current() zapcore.Core {
+	base := c.ref.Load()
+	if len(c.fields) == 0 {
+		return base.Core
+	}
+	if derived := c.cache.Load(); derived != nil && derived.base == base {
+		return derived.core
+	}
+	core := base.Core.With(c.fields)
+	c.cache.Store(&derivedCore{base: base, core: core})
+	return core
+}
+
+func (c *swapCore) Enabled(level zapcore.Level) bool {
+	return c.current().Enabled(level)
+}
+
+func (c *swapCore) Level() zapcore.Level {
+	return zapcore.LevelOf(c.current())
+}
+
+func (c *swapCore) With(fields []zapcore.Field) zapcore.Core {
+	if len(fields) == 0 {
+		return c
+	}
+	merged := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	merged = append(merged, c.fields...)
+	merged = append(merged, fields...)
+	return &swapCore{ref: c.ref, fields: merged}
+}
+
+func (c *swapCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return c.current().Check(ent, ce)
+}
+
+func (c *swapCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.current().Write(ent, fields)
+}
+
+func (c *swapCore) Sync() error {
+	return c.current().Sync()
+}
@@ -0,0 +1,25 @@
+package log
+
+import (
+	"os"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultCore 默认logger的core
+var defaultCore zapcore.Core
+
+// 调用InitLogger之前使用默认logger：debug级别、带颜色及调用位置的console格式，输出到stderr。
+// 全局logger及由其派生的Logger在InitLogger之后自动使用正式配置，包初始化时获取的Logger同样有效
+func init() {
+	globalState.level.SetLevel(zapcore.DebugLevel)
+	defaultCore = newDefaultCore(stdWriter{os.Stderr}, globalState.level)
+	globalState.core.Store(defaultCore)
+	global = globalState.newLogger()
+}
+
+// newDefaultCore 创建默认logger的core
+func newDefaultCore(ws zapcore.WriteSyncer, level zapcore.LevelEnabler) zapcore.Core {
+	base := ConfigBase{Format: FormatConsole, ShowLineNumber: true, Encoder: EncoderConfig{Preset: PresetDev}}
+	return newRedactor(RedactConfig{}).wrap(zapcore.NewCore(newEncoder(base, SinkConfig{}), ws, level))
+}
@@ -0,0 +1,50 @@
+package log
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestDefaultLoggerBeforeInit(t *testing.T) {
+	resetLoggers(t)
+	logLock.Lock()
+	initialized, previous := initialize, globalState.core.Load()
+	before := &lockedBuffer{}
+	initialize = false
+	globalState.level.SetLevel(zapcore.DebugLevel)
+	globalState.core.Store(newDefaultCore(before, globalState.level))
+	logLock.Unlock()
+	t.Cleanup(func() {
+		logLock.Lock()
+		defer logLock.Unlock()
+		initialize = initialized
+		if !initialized {
+			globalState.core.Store(previous)
+		}
+	})
+
+	http := Get("http")
+	http.Debug("before init")
+	Named("app").Info("named before init")
+	if lines := before.lines(); len(lines) != 2 || !strings.Contains(lines[0], "DEBUG") || !strings.Contains(lines[0], "\thttp\t") ||
+		!strings.Contains(lines[0], "default_test.go") || !strings.Contains(lines[1], "named before init") {
+		t.Fatalf("unexpected default output: %q", lines)
+	}
+
+	after := &lockedBuffer{}
+	registerTestSink(t, "default-test-buffer", after)
+	InitLogger(GlobalConfig{Level: "info", Sinks: []SinkConfig{{Type: "default-test-buffer", Format: FormatJSON}}})
+	http.Debug("filtered")
+	http.Info("after init")
+	if lines := after.lines(); len(lines) != 1 || !strings.Contains(lines[0], `"logger":"http"`) {
+		t.Fatalf("handle obtained before init should use the configured sink: %q", lines)
+	}
+	if len(before.lines()) != 2 {
+		t.Fatalf("default logger should not be used after init: %q", before.lines())
+	}
+	if http.l.Core().Enabled(zapcore.DebugLevel) {
+		t.Fatal("configured level should apply after init")
+	}
+}
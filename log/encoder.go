@@ -0,0 +1,248 @@
+package log
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Format 日志输出格式
+type Format string
+
+// 支持的输出格式
+const (
+	FormatJSON    Format = "json"
+	FormatConsole Format = "console"
+	FormatLogfmt  Format = "logfmt"
+)
+
+// 内置的编码预设
+const (
+	PresetECS            = "ecs"
+	PresetGCP            = "gcp"
+	PresetLogfmtFriendly = "logfmt-friendly"
+	PresetDev            = "dev"
+)
+
+// EncoderConfig 编码配置，先按Preset取得预设，再用非空字段逐项覆盖；字段名设置为"-"时不输出该字段
+type EncoderConfig struct {
+	// Preset 预设，ecs、gcp、logfmt-friendly、dev，为空时使用默认配置
+	Preset        string `yaml:"preset" json:"preset" mapstructure:"preset" validate:"omitempty,oneof=ecs gcp logfmt-friendly dev"`
+	TimeKey       string `yaml:"time_key" json:"time_key" mapstructure:"time_key"`
+	LevelKey      string `yaml:"level_key" json:"level_key" mapstructure:"level_key"`
+	NameKey       string `yaml:"name_key" json:"name_key" mapstructure:"name_key"`
+	CallerKey     string `yaml:"caller_key" json:"caller_key" mapstructure:"caller_key"`
+	FunctionKey   string `yaml:"function_key" json:"function_key" mapstructure:"function_key"`
+	MessageKey    string `yaml:"message_key" json:"message_key" mapstructure:"message_key"`
+	StacktraceKey string `yaml:"stacktrace_key" json:"stacktrace_key" mapstructure:"stacktrace_key"`
+	// TimeEncoder 时间格式，iso8601、rfc3339、rfc3339nano、epoch、epoch_millis、epoch_nanos
+	TimeEncoder string `yaml:"time_encoder" json:"time_encoder" mapstructure:"time_encoder" validate:"omitempty,oneof=iso8601 rfc3339 rfc3339nano epoch epoch_millis epoch_nanos"`
+	// LevelEncoder 级别格式，capital、capital_color、lowercase、color、gcp
+	LevelEncoder string `yaml:"level_encoder" json:"level_encoder" mapstructure:"level_encoder" validate:"omitempty,oneof=capital capital_color lowercase color gcp"`
+	// CallerEncoder 调用位置格式，short、full
+	CallerEncoder string `yaml:"caller_encoder" json:"caller_encoder" mapstructure:"caller_encoder" validate:"omitempty,oneof=short full"`
+	// DurationEncoder 时长格式，seconds、millis、nanos、string
+	DurationEncoder string `yaml:"duration_encoder" json:"duration_encoder" mapstructure:"duration_encoder" validate:"omitempty,oneof=seconds millis nanos string"`
+}
+
+// getEncoderConfig 设置zap输出格式内容
+func getEncoderConfig() zapcore.EncoderConfig {
+	return zapcore.EncoderConfig{
+		TimeKey:        "time",
+		LevelKey:       "level",
+		NameKey:        "logger",
+		CallerKey:      "caller",
+		MessageKey:     "msg",
+		StacktraceKey:  "stacktrace",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.CapitalLevelEncoder,
+		EncodeTime:     zapcore.ISO8601TimeEncoder,
+		EncodeDuration: zapcore.SecondsDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+		EncodeName:     zapcore.FullNameEncoder,
+	}
+}
+
+// presetEncoderConfig 获取预设编码配置，未知预设返回默认配置
+func presetEncoderConfig(preset string) zapcore.EncoderConfig {
+	config := getEncoderConfig()
+	switch preset {
+	case PresetECS:
+		config.TimeKey = "@timestamp"
+		config.LevelKey = "log.level"
+		config.NameKey = "log.logger"
+		config.CallerKey = "log.origin.file"
+		config.FunctionKey = "log.origin.function"
+		config.MessageKey = "message"
+		config.StacktraceKey = "error.stack_trace"
+		config.EncodeLevel = zapcore.LowercaseLevelEncoder
+		config.EncodeTime = zapcore.ISO8601TimeEncoder
+		config.EncodeDuration = zapcore.NanosDurationEncoder
+	case PresetGCP:
+		config.TimeKey = "time"
+		config.LevelKey = "severity"
+		config.MessageKey = "message"
+		config.StacktraceKey = "stack_trace"
+		config.EncodeLevel = gcpLevelEncoder
+		config.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+		config.EncodeDuration = zapcore.MillisDurationEncoder
+	case PresetLogfmtFriendly:
+		config.TimeKey = "ts"
+		config.EncodeLevel = zapcore.LowercaseLevelEncoder
+		config.EncodeTime = zapcore.RFC3339TimeEncoder
+		config.EncodeDuration = zapcore.StringDurationEncoder
+	case PresetDev:
+		config = zap.NewDevelopmentEncoderConfig()
+		config.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
+	return config
+}
+
+// merge 合并编码配置，override设置了Preset时以override为准，否则仅覆盖其非空字段
+func (c EncoderConfig) merge(override EncoderConfig) EncoderConfig {
+	if override.Preset != "" {
+		return override
+	}
+	for _, pair := range []struct{ dst, src *string }{
+		{&c.TimeKey, &override.TimeKey},
+		{&c.LevelKey, &override.LevelKey},
+		{&c.NameKey, &override.NameKey},
+		{&c.CallerKey, &override.CallerKey},
+		{&c.FunctionKey, &override.FunctionKey},
+		{&c.MessageKey, &override.MessageKey},
+		{&c.StacktraceKey, &override.StacktraceKey},
+		{&c.TimeEncoder, &override.TimeEncoder},
+		{&c.LevelEncoder, &override.LevelEncoder},
+		{&c.CallerEncoder, &override.CallerEncoder},
+		{&c.DurationEncoder, &override.DurationEncoder},
+	} {
+		if *pair.src != "" {
+			*pair.dst = *pair.src
+		}
+	}
+	return c
+}
+
+// build 转换为zapcore.EncoderConfig
+func (c EncoderConfig) build() zapcore.EncoderConfig {
+	config := presetEncoderConfig(c.Preset)
+	for _, pair := range []struct {
+		dst *string
+		src string
+	}{
+		{&config.TimeKey, c.TimeKey},
+		{&config.LevelKey, c.LevelKey},
+		{&config.NameKey, c.NameKey},
+		{&config.CallerKey, c.CallerKey},
+		{&config.FunctionKey, c.FunctionKey},
+		{&config.MessageKey, c.MessageKey},
+		{&config.StacktraceKey, c.StacktraceKey},
+	} {
+		switch pair.src {
+		case "":
+		case "-":
+			*pair.dst = zapcore.OmitKey
+		default:
+			*pair.dst = pair.src
+		}
+	}
+
+	switch c.TimeEncoder {
+	case "iso8601":
+		config.EncodeTime = zapcore.ISO8601TimeEncoder
+	case "rfc3339":
+		config.EncodeTime = zapcore.RFC3339TimeEncoder
+	case "rfc3339nano":
+		config.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+	case "epoch":
+		config.EncodeTime = zapcore.EpochTimeEncoder
+	case "epoch_millis":
+		config.EncodeTime = zapcore.EpochMillisTimeEncoder
+	case "epoch_nanos":
+		config.EncodeTime = zapcore.EpochNanosTimeEncoder
+	}
+	switch c.LevelEncoder {
+	case "capital":
+		config.EncodeLevel = zapcore.CapitalLevelEncoder
+	case "capital_color":
+		config.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	case "lowercase":
+		config.EncodeLevel = zapcore.LowercaseLevelEncoder
+	case "color":
+		config.EncodeLevel = zapcore.LowercaseColorLevelEncoder
+	case "gcp":
+		config.EncodeLevel = gcpLevelEncoder
+	}
+	switch c.CallerEncoder {
+	case "short":
+		config.EncodeCaller = zapcore.ShortCallerEncoder
+	case "full":
+		config.EncodeCaller = zapcore.FullCallerEncoder
+	}
+	switch c.DurationEncoder {
+	case "seconds":
+		config.EncodeDuration = zapcore.SecondsDurationEncoder
+	case "millis":
+		config.EncodeDuration = zapcore.MillisDurationEncoder
+	case "nanos":
+		config.EncodeDuration = zapcore.NanosDurationEncoder
+	case "string":
+		config.EncodeDuration = zapcore.StringDurationEncoder
+	}
+	return config
+}
+
+// gcpLevelEncoder 按Google Cloud Logging的severity输出日志级别
+func gcpLevelEncoder(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	switch l {
+	case zapcore.DebugLevel:
+		enc.AppendString("DEBUG")
+	case zapcore.InfoLevel:
+		enc.AppendString("INFO")
+	case zapcore.WarnLevel:
+		enc.AppendString("WARNING")
+	case zapcore.ErrorLevel:
+		enc.AppendString("ERROR")
+	case zapcore.DPanicLevel:
+		enc.AppendString("CRITICAL")
+	case zapcore.PanicLevel:
+		enc.AppendString("ALERT")
+	case zapcore.FatalLevel:
+		enc.AppendString("EMERGENCY")
+	default:
+		enc.AppendString("DEFAULT")
+	}
+}
+
+// format 返回实际使用的输出格式，未设置Format时按JSONFormat选择
+func (c ConfigBase) format() Format {
+	switch {
+	case c.Format != "":
+		return c.Format
+	case c.JSONFormat:
+		return FormatJSON
+	}
+	return FormatConsole
+}
+
+// newEncoder 根据logger配置及输出配置创建encoder，输出未指定格式时使用logger的格式
+func newEncoder(base ConfigBase, sink SinkConfig) zapcore.Encoder {
+	encoderConfig := base.Encoder.merge(sink.Encoder).build()
+	if !base.ShowLineNumber {
+		encoderConfig.CallerKey = zapcore.OmitKey
+		encoderConfig.FunctionKey = zapcore.OmitKey
+	}
+	format := sink.Format
+	if format == "" {
+		format = base.format()
+	}
+	switch format {
+	case FormatJSON:
+		return zapcore.NewJSONEncoder(encoderConfig)
+	case FormatLogfmt:
+		return NewLogfmtEncoder(encoderConfig)
+	}
+	if sink.Color && sink.Encoder.LevelEncoder == "" {
+		encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
+	return zapcore.NewConsoleEncoder(encoderConfig)
+}
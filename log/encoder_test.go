@@ -0,0 +1,64 @@
+package log
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func encodeEntry(t *testing.T, base ConfigBase, sink SinkConfig) map[string]interface{} {
+	t.Helper()
+	entry := zapcore.Entry{
+		Level:      zapcore.WarnLevel,
+		Time:       time.Date(2026, 10, 17, 8, 30, 0, 0, time.UTC),
+		LoggerName: "orders",
+		Message:    "hello",
+		Caller:     zapcore.NewEntryCaller(0, "/src/app/orders/handler.go", 42, true),
+	}
+	entry.Caller.Function = "app/orders.Handle"
+	buf, err := newEncoder(base, sink).EncodeEntry(entry, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid json %q: %v", buf.String(), err)
+	}
+	return out
+}
+
+func TestEncoderPresets(t *testing.T) {
+	ecs := encodeEntry(t, ConfigBase{JSONFormat: true, ShowLineNumber: true, Encoder: EncoderConfig{Preset: PresetECS}}, SinkConfig{})
+	if ecs["@timestamp"] == nil || ecs["log.level"] != "warn" || ecs["message"] != "hello" || ecs["log.origin.function"] != "app/orders.Handle" {
+		t.Fatalf("unexpected ecs entry %v", ecs)
+	}
+
+	gcp := encodeEntry(t, ConfigBase{JSONFormat: true, Encoder: EncoderConfig{Preset: PresetGCP}}, SinkConfig{})
+	if gcp["severity"] != "WARNING" || gcp["time"] != "2026-10-17T08:30:00Z" || gcp["caller"] != nil {
+		t.Fatalf("unexpected gcp entry %v", gcp)
+	}
+
+	// 输出级别的配置覆盖logger级别的配置
+	overridden := encodeEntry(t,
+		ConfigBase{JSONFormat: true, ShowLineNumber: true, Encoder: EncoderConfig{Preset: PresetGCP, CallerEncoder: "full"}},
+		SinkConfig{Encoder: EncoderConfig{TimeEncoder: "epoch_millis", LevelKey: "-", MessageKey: "text"}})
+	if overridden["time"] != float64(1792225800000) || overridden["severity"] != nil || overridden["text"] != "hello" ||
+		!strings.HasPrefix(overridden["caller"].(string), "/src/app/orders/") {
+		t.Fatalf("unexpected overridden entry %v", overridden)
+	}
+}
+
+func TestEncoderConfigValidation(t *testing.T) {
+	_, err := LoadConfig(strings.NewReader(`
+global:
+  encoder:
+    preset: loki
+    time_encoder: unix
+`), "yaml")
+	if err == nil || !strings.Contains(err.Error(), "global.encoder.preset") || !strings.Contains(err.Error(), "global.encoder.time_encoder") {
+		t.Fatalf("invalid encoder config should fail, got %v", err)
+	}
+}
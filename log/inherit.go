@@ -0,0 +1,90 @@
+package log
+
+import (
+	"reflect"
+	"strings"
+)
+
+// child 将全局配置转换为子logger配置，作为继承的起点
+func (c GlobalConfig) child() ChildConfig {
+	return ChildConfig{
+		Level:         c.Level,
+		EnableFileLog: c.EnableFileLog,
+		Sinks:         c.Sinks,
+		Outputs:       c.Outputs,
+		ConfigBase:    c.ConfigBase,
+		FileLogConfig: c.FileLogConfig,
+	}
+}
+
+// resolveChildConfig 返回子logger生效的配置，Inherit为true时未设置的字段取自最近的已配置祖先，
+// 如kafka.consumer依次查找kafka及全局配置
+func resolveChildConfig(config ChildConfig, global GlobalConfig, children map[string]ChildConfig) ChildConfig {
+	if !config.Inherit {
+		return config
+	}
+	return inheritConfig(config, ancestorConfig(config.LoggerName, global, children))
+}
+
+// ancestorConfig 查找最近的已配置祖先的生效配置，没有时使用全局配置
+func ancestorConfig(name string, global GlobalConfig, children map[string]ChildConfig) ChildConfig {
+	for i := strings.LastIndexByte(name, '.'); i > 0; i = strings.LastIndexByte(name[:i], '.') {
+		if parent, ok := children[name[:i]]; ok {
+			return resolveChildConfig(parent, global, children)
+		}
+	}
+	return global.child()
+}
+
+// inheritConfig 用parent填充config中未设置的字段；EnableFileLog、Sinks及Outputs决定输出目标，
+// 三者均未设置时整体继承，其余字段逐个继承，结构体类型的字段(如Encoder、Sampling)为零值时整体继承
+// NoInherit中列出的字段不继承
+func inheritConfig(config, parent ChildConfig) ChildConfig {
+	skip := make(map[string]bool, len(config.NoInherit))
+	for _, name := range config.NoInherit {
+		skip[name] = true
+	}
+	if config.Level == "" && !skip["level"] {
+		config.Level = parent.Level
+	}
+	if !config.EnableFileLog && len(config.Sinks) == 0 && len(config.Outputs) == 0 {
+		config.EnableFileLog, config.Sinks, config.Outputs = parent.EnableFileLog, parent.Sinks, parent.Outputs
+	}
+	inheritZero(reflect.ValueOf(&config.ConfigBase).Elem(), reflect.ValueOf(parent.ConfigBase), skip)
+	inheritZero(reflect.ValueOf(&config.FileLogConfig).Elem(), reflect.ValueOf(parent.FileLogConfig), skip)
+	return config
+}
+
+// inheritZero 将dst中的零值字段设置为src中对应字段的值，skip中的字段(按yaml名称)除外
+func inheritZero(dst, src reflect.Value, skip map[string]bool) {
+	for i := 0; i < dst.NumField(); i++ {
+		if field := dst.Field(i); field.IsZero() && !skip[yamlName(dst.Type().Field(i))] {
+			field.Set(src.Field(i))
+		}
+	}
+}
+
+func yamlName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	return name
+}
+
+// inheritable 返回NoInherit中可以使用的字段名称
+func inheritable() map[string]bool {
+	names := map[string]bool{"level": true}
+	for _, t := range []reflect.Type{reflect.TypeOf(ConfigBase{}), reflect.TypeOf(FileLogConfig{})} {
+		for i := 0; i < t.NumField(); i++ {
+			names[yamlName(t.Field(i))] = true
+		}
+	}
+	return names
+}
+
+// resolvedChildConfigs 返回全部子logger生效的配置
+func resolvedChildConfigs(global GlobalConfig, children map[string]ChildConfig) map[string]ChildConfig {
+	resolved := make(map[string]ChildConfig, len(children))
+	for name, config := range children {
+		resolved[name] = resolveChildConfig(config, global, children)
+	}
+	return resolved
+}
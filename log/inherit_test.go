@@ -0,0 +1,101 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInheritConfig(t *testing.T) {
+	resetLoggers(t)
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "app.log")
+	global := GlobalConfig{
+		Level:         "warn",
+		EnableFileLog: true,
+		ConfigBase:    ConfigBase{Format: FormatJSON, Encoder: EncoderConfig{MessageKey: "message"}},
+		FileLogConfig: FileLogConfig{FileName: fileName},
+	}
+	err := InitLoggerE(global,
+		ChildConfig{LoggerName: "kafka", Inherit: true, Level: "debug"},
+		ChildConfig{LoggerName: "kafka.consumer", Inherit: true},
+		ChildConfig{LoggerName: "plain"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"kafka": "debug", "kafka.consumer": "debug", "plain": "info"} {
+		if level, _ := GetLevel(name); level != want {
+			t.Errorf("%s level = %s, want %s", name, level, want)
+		}
+	}
+
+	GetLoggerWithFileName("kafka.consumer").Debug("inherited entry")
+	content, _ := os.ReadFile(fileName)
+	line := strings.TrimSpace(string(content))
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil || entry["message"] != "inherited entry" || entry["logger"] != "kafka.consumer" {
+		t.Fatalf("child should inherit json format, encoder and file from global, got %q (%v)", line, err)
+	}
+
+	// 全局级别变化后，未设置级别的继承logger随之变化
+	global.Level = "error"
+	InitLogger(global)
+	if level, _ := GetLevel("kafka.consumer"); level != "debug" {
+		t.Fatalf("kafka.consumer should still follow kafka, got %s", level)
+	}
+	if err := AddChildLoggerE(ChildConfig{LoggerName: "db", Inherit: true, EnableFileLog: true}); err != nil {
+		t.Fatalf("file_name should be inherited from global: %v", err)
+	}
+	if level, _ := GetLevel("db"); level != "error" {
+		t.Fatalf("db should inherit the global level, got %s", level)
+	}
+}
+
+func TestInheritOverrides(t *testing.T) {
+	parent := ChildConfig{
+		Level:      "warn",
+		Sinks:      []SinkConfig{{Type: SinkStdout}},
+		ConfigBase: ConfigBase{Format: FormatJSON, ShowLineNumber: true, Sampling: SamplingConfig{First: 10}},
+	}
+	got := inheritConfig(ChildConfig{
+		LoggerName: "child",
+		Outputs:    []OutputConfig{{FileLogConfig: FileLogConfig{FileName: "child.log"}}},
+		ConfigBase: ConfigBase{Format: FormatLogfmt},
+	}, parent)
+	if got.Level != "warn" || got.Format != FormatLogfmt || !got.ShowLineNumber || got.Sampling.First != 10 {
+		t.Fatalf("unexpected inherited config %+v", got)
+	}
+	if len(got.Sinks) != 0 || len(got.Outputs) != 1 {
+		t.Fatal("outputs configured on the child should not be mixed with the parent's sinks")
+	}
+}
+
+func TestNoInherit(t *testing.T) {
+	parent := ChildConfig{
+		Level:         "warn",
+		ConfigBase:    ConfigBase{JSONFormat: true, ShowLineNumber: true},
+		FileLogConfig: FileLogConfig{FileName: "app.log", MaxAge: 7, Compress: true},
+	}
+	got := inheritConfig(ChildConfig{
+		LoggerName: "child",
+		Inherit:    true,
+		NoInherit:  []string{"show_line_number", "compress", "max_age"},
+	}, parent)
+	if got.ShowLineNumber || got.Compress || got.MaxAge != 0 {
+		t.Fatalf("fields listed in NoInherit should keep their zero values, got %+v", got)
+	}
+	if got.Level != "warn" || !got.JSONFormat || got.FileName != "app.log" {
+		t.Fatalf("other fields should still be inherited, got %+v", got)
+	}
+
+	resetLoggers(t)
+	err := InitLoggerE(GlobalConfig{Level: "info"}, ChildConfig{LoggerName: "child", Inherit: true, NoInherit: []string{"show_line", "level"}})
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || len(configErr.Errors) != 1 || configErr.Errors[0].Path != "children[0].no_inherit[0]" {
+		t.Fatalf("unknown NoInherit fields should be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package log
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ObjectMarshaler 可编码为对象的字段值，见zap.Object
+type ObjectMarshaler = zapcore.ObjectMarshaler
+
+// Lazy 延迟构建的字段，仅在日志实际被某个输出编码时调用fn，同一条日志输出到多个目标时只调用一次；
+// 日志级别未开启、被采样或限流丢弃时不会调用；通过With添加时在With时调用
+func Lazy(fn func() Field) Field {
+	return zap.Inline(&lazyField{fn: fn})
+}
+
+// LazyObject 延迟构建的对象字段，调用时机与Lazy相同
+func LazyObject(key string, fn func() ObjectMarshaler) Field {
+	return zap.Object(key, &lazyObject{fn: fn})
+}
+
+type lazyField struct {
+	once  sync.Once
+	fn    func() Field
+	field Field
+}
+
+func (f *lazyField) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	f.once.Do(func() {
+		f.field = f.fn()
+	})
+	f.field.AddTo(enc)
+	return nil
+}
+
+type lazyObject struct {
+	once   sync.Once
+	fn     func() ObjectMarshaler
+	object ObjectMarshaler
+}
+
+func (o *lazyObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	o.once.Do(func() {
+		o.object = o.fn()
+	})
+	if o.object == nil {
+		return nil
+	}
+	return o.object.MarshalLogObject(enc)
+}
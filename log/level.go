@@ -0,0 +1,141 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+// SetLevel 运行时调整日志级别，name为空时调整全局logger，否则调整对应的子logger
+func SetLevel(name, level string) error {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	atomicLevel, err := lookupLevel(name)
+	if err != nil {
+		return err
+	}
+	atomicLevel.SetLevel(lvl)
+	return nil
+}
+
+// GetLevel 获取当前日志级别，name为空时返回全局logger的级别
+func GetLevel(name string) (string, error) {
+	atomicLevel, err := lookupLevel(name)
+	if err != nil {
+		return "", err
+	}
+	return atomicLevel.Level().String(), nil
+}
+
+// lookupLevel 根据logger名称查找对应的AtomicLevel
+func lookupLevel(name string) (zap.AtomicLevel, error) {
+	logLock.RLock()
+	defer logLock.RUnlock()
+	if name == "" {
+		return globalState.level, nil
+	}
+	if state, ok := childStates[name]; ok {
+		return state.level, nil
+	}
+	return zap.AtomicLevel{}, fmt.Errorf("log: unknown logger %q", name)
+}
+
+// allLevels 返回全局logger及所有子logger当前的日志级别
+func allLevels() (string, map[string]string) {
+	logLock.RLock()
+	defer logLock.RUnlock()
+	children := make(map[string]string, len(childStates))
+	for name, state := range childStates {
+		children[name] = state.level.Level().String()
+	}
+	return globalState.level.Level().String(), children
+}
+
+type levelPayload struct {
+	Name  string `json:"name,omitempty"`
+	Level string `json:"level"`
+}
+
+type levelsPayload struct {
+	Global  string         `json:"global"`
+	Loggers []levelPayload `json:"loggers"`
+}
+
+type errorPayload struct {
+	Error string `json:"error"`
+}
+
+// LevelHandler 返回用于查看和调整日志级别的http.Handler，用法与zap.AtomicLevel.ServeHTTP类似
+//
+// GET 不带name参数时列出全局logger及所有子logger的级别，带name参数时返回单个logger的级别；
+// PUT 通过query/form参数或JSON body {"name": "kafka", "level": "debug"} 修改级别，name为空时修改全局logger
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(serveLevel)
+}
+
+func serveLevel(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+
+	switch r.Method {
+	case http.MethodGet:
+		if name, ok := r.URL.Query()["name"]; ok {
+			level, err := GetLevel(name[0])
+			if err != nil {
+				w.WriteHeader(http.StatusNotFound)
+				enc.Encode(errorPayload{Error: err.Error()})
+				return
+			}
+			enc.Encode(levelPayload{Name: name[0], Level: level})
+			return
+		}
+		globalLvl, children := allLevels()
+		payload := levelsPayload{Global: globalLvl, Loggers: make([]levelPayload, 0, len(children))}
+		for name, level := range children {
+			payload.Loggers = append(payload.Loggers, levelPayload{Name: name, Level: level})
+		}
+		sort.Slice(payload.Loggers, func(i, j int) bool {
+			return payload.Loggers[i].Name < payload.Loggers[j].Name
+		})
+		enc.Encode(payload)
+	case http.MethodPut:
+		req, err := decodeLevelRequest(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			enc.Encode(errorPayload{Error: err.Error()})
+			return
+		}
+		if err := SetLevel(req.Name, req.Level); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			enc.Encode(errorPayload{Error: err.Error()})
+			return
+		}
+		enc.Encode(req)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		enc.Encode(errorPayload{Error: "Only GET and PUT are supported."})
+	}
+}
+
+// decodeLevelRequest 解析PUT请求，支持form参数和JSON body
+func decodeLevelRequest(r *http.Request) (levelPayload, error) {
+	var req levelPayload
+	if r.Header.Get("Content-Type") == "application/x-www-form-urlencoded" || r.URL.Query().Has("level") {
+		req.Name = r.FormValue("name")
+		req.Level = r.FormValue("level")
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return req, fmt.Errorf("malformed request body: %v", err)
+	}
+	if req.Level == "" {
+		return req, fmt.Errorf("must specify logging level")
+	}
+	if _, err := ParseLevel(req.Level); err != nil {
+		return req, err
+	}
+	return req, nil
+}
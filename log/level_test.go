@@ -0,0 +1,50 @@
+package log
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLevelHandler(t *testing.T) {
+	resetLoggers(t)
+	InitLogger(GlobalConfig{Level: "info"}, ChildConfig{LoggerName: "kafka", Level: "warn"})
+	handler := LevelHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if want := `{"global":"info","loggers":[{"name":"kafka","level":"warn"}]}`; strings.TrimSpace(rec.Body.String()) != want {
+		t.Fatalf("list levels = %s, want %s", rec.Body.String(), want)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"name":"kafka","level":"debug"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	if level, _ := GetLevel("kafka"); level != "debug" {
+		t.Fatalf("kafka level = %s, want debug", level)
+	}
+	if !GetLoggerWithFileName("kafka").l.Core().Enabled(zapcore.DebugLevel) {
+		t.Fatal("kafka logger should be enabled for debug after PUT")
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/?level=error", nil))
+	if level, _ := GetLevel(""); level != "error" {
+		t.Fatalf("global level = %s, want error", level)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/?name=kafka&level=warning", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("PUT unknown level status = %d, want 400", rec.Code)
+	}
+
+	if err := SetLevel("missing", "info"); err == nil {
+		t.Fatal("SetLevel on unknown logger should fail")
+	}
+}
@@ -2,29 +2,39 @@ package log
 
 import (
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
 func TestLogger(t *testing.T) {
+	dir := t.TempDir()
 	base := ConfigBase{
-		MaxSize:        500,  // 但文件大小，单位：MB
-		MaxBackups:     100,  // 备份文件个数
-		MaxAge:         14,   // 最大保存天数
-		Compress:       true, // 备份文件是否压缩保存
 		JSONFormat:     true, // 日志打印格式，是否启用json 格式
 		ShowLineNumber: true, // 是否显示打印位置信息，类、行号等
 	}
+	file := FileLogConfig{
+		MaxSize:    500,  // 但文件大小，单位：MB
+		MaxBackups: 100,  // 备份文件个数
+		MaxAge:     14,   // 最大保存天数
+		Compress:   true, // 备份文件是否压缩保存
+	}
 	config := GlobalConfig{
-		Level:       "info",          // 日志级别
-		LogFileName: "logs/root.log", // 日志文件 全路径名
-		ConfigBase:  base,
+		Level:         "info", // 日志级别
+		EnableFileLog: true,
+		ConfigBase:    base,
+		FileLogConfig: file,
 	}
+	config.FileName = filepath.Join(dir, "root.log") // 日志文件 全路径名
 	kafka := ChildConfig{
-		LoggerName:  "kafka",
-		Level:       "info",
-		LogFileName: "logs/kafka.log",
-		ConfigBase:  base,
+		LoggerName:    "kafka",
+		Level:         "info",
+		EnableFileLog: true,
+		ConfigBase:    base,
+		FileLogConfig: file,
 	}
+	kafka.FileName = filepath.Join(dir, "kafka.log")
 	InitLogger(config, kafka)
 
 	Info("test", zap.String("trace", "aaa"), zap.Int64("id", 123456))
@@ -33,3 +43,81 @@ func TestLogger(t *testing.T) {
 	GetLogger().With(zap.String("sub", "t")).Info("hhhh")
 	GetLoggerWithFileName("kafka").Named("consumer").Info("hello consumer")
 }
+
+// registerTestSink 注册测试使用的输出，测试结束后注销
+func registerTestSink(t testing.TB, name string, ws zapcore.WriteSyncer) {
+	t.Helper()
+	if err := RegisterSink(name, ws); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { UnregisterSink(name) })
+}
+
+// resetLoggers 清空子logger注册信息，避免测试之间互相影响
+func resetLoggers(t testing.TB) {
+	t.Helper()
+	logLock.Lock()
+	defer logLock.Unlock()
+	for _, w := range asyncWriters {
+		w.stop()
+	}
+	asyncWriters = nil
+	for _, l := range rateLimiters {
+		l.stop()
+	}
+	rateLimiters = nil
+	for _, hook := range files {
+		hook.Close()
+	}
+	files = make(map[string]rotatingFile)
+	loggers = make(map[string]*Logger)
+	childStates = make(map[string]*loggerState)
+	childConfigs = make(map[string]ChildConfig)
+	autoLoggers = make(map[string]bool)
+	levelRules = nil
+	globalState.levelSource = nil
+}
+
+func TestShowLineNumber(t *testing.T) {
+	resetLoggers(t)
+	buf := &lockedBuffer{}
+	registerTestSink(t, "caller-test-buffer", buf)
+	sinks := []SinkConfig{{Type: "caller-test-buffer", Format: FormatJSON}}
+	err := InitLoggerE(GlobalConfig{Level: "info", Sinks: sinks}, ChildConfig{
+		LoggerName: "lines",
+		Level:      "info",
+		Sinks:      sinks,
+		ConfigBase: ConfigBase{ShowLineNumber: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if globalState.caller.Load() || !childStates["lines"].caller.Load() {
+		t.Fatal("caller should only be captured when ShowLineNumber is enabled")
+	}
+	Info("plain")
+	GetLoggerWithFileName("lines").With(String("k", "v")).Info("with caller")
+	lines := buf.lines()
+	if len(lines) != 2 || strings.Contains(lines[0], `"caller"`) || !strings.Contains(lines[1], `"caller":"log/log_test.go:`) {
+		t.Fatalf("unexpected output %q", lines)
+	}
+}
+
+func BenchmarkShowLineNumber(b *testing.B) {
+	for _, show := range []bool{false, true} {
+		b.Run(map[bool]string{false: "off", true: "on"}[show], func(b *testing.B) {
+			resetLoggers(b)
+			InitLogger(GlobalConfig{
+				Level:      "info",
+				ConfigBase: ConfigBase{ShowLineNumber: show},
+				Sinks:      []SinkConfig{{Type: SinkDiscard}},
+			})
+			logger := GetLogger()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				logger.Info("benchmark", Int("i", i))
+			}
+		})
+	}
+}
@@ -0,0 +1,556 @@
+package log
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+const hex = "0123456789abcdef"
+
+var (
+	bufferPool = buffer.NewPool()
+	logfmtPool = sync.Pool{New: func() interface{} {
+		return &logfmtEncoder{}
+	}}
+)
+
+// logfmtEncoder logfmt格式的zapcore.Encoder，嵌套对象、反射编码的map及struct、Namespace使用点号连接的key平铺输出
+type logfmtEncoder struct {
+	*zapcore.EncoderConfig
+	buf *buffer.Buffer
+	// prefix 当前嵌套对象或Namespace的key前缀，如"req."
+	prefix string
+}
+
+// NewLogfmtEncoder 创建logfmt格式的encoder，key中的非法字符替换为下划线，值在需要时加引号并转义
+func NewLogfmtEncoder(config zapcore.EncoderConfig) zapcore.Encoder {
+	return &logfmtEncoder{EncoderConfig: &config, buf: bufferPool.Get()}
+}
+
+func getLogfmtEncoder(config *zapcore.EncoderConfig, prefix string) *logfmtEncoder {
+	enc := logfmtPool.Get().(*logfmtEncoder)
+	enc.EncoderConfig = config
+	enc.buf = bufferPool.Get()
+	enc.prefix = prefix
+	return enc
+}
+
+func putLogfmtEncoder(enc *logfmtEncoder) {
+	enc.EncoderConfig = nil
+	enc.buf = nil
+	enc.prefix = ""
+	logfmtPool.Put(enc)
+}
+
+func (enc *logfmtEncoder) Clone() zapcore.Encoder {
+	clone := getLogfmtEncoder(enc.EncoderConfig, enc.prefix)
+	clone.buf.Write(enc.buf.Bytes())
+	return clone
+}
+
+func (enc *logfmtEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := getLogfmtEncoder(enc.EncoderConfig, "")
+	defer putLogfmtEncoder(final)
+
+	if final.TimeKey != "" && !ent.Time.IsZero() {
+		final.AddTime(final.TimeKey, ent.Time)
+	}
+	if final.LevelKey != "" && final.EncodeLevel != nil {
+		final.addKey(final.LevelKey)
+		final.appendEncoded(func(arr zapcore.PrimitiveArrayEncoder) { final.EncodeLevel(ent.Level, arr) })
+	}
+	if final.NameKey != "" && ent.LoggerName != "" {
+		final.addKey(final.NameKey)
+		if final.EncodeName != nil {
+			final.appendEncoded(func(arr zapcore.PrimitiveArrayEncoder) { final.EncodeName(ent.LoggerName, arr) })
+		} else {
+			final.appendString(ent.LoggerName)
+		}
+	}
+	if ent.Caller.Defined {
+		if final.CallerKey != "" && final.EncodeCaller != nil {
+			final.addKey(final.CallerKey)
+			final.appendEncoded(func(arr zapcore.PrimitiveArrayEncoder) { final.EncodeCaller(ent.Caller, arr) })
+		}
+		if final.FunctionKey != "" {
+			final.AddString(final.FunctionKey, ent.Caller.Function)
+		}
+	}
+	if final.MessageKey != "" {
+		final.AddString(final.MessageKey, ent.Message)
+	}
+	if enc.buf.Len() > 0 {
+		final.separate()
+		final.buf.Write(enc.buf.Bytes())
+	}
+	final.prefix = enc.prefix
+	for i := range fields {
+		fields[i].AddTo(final)
+	}
+	final.prefix = ""
+	if ent.Stack != "" && final.StacktraceKey != "" {
+		final.AddString(final.StacktraceKey, ent.Stack)
+	}
+	if final.LineEnding != "" {
+		final.buf.AppendString(final.LineEnding)
+	} else {
+		final.buf.AppendString(zapcore.DefaultLineEnding)
+	}
+	return final.buf, nil
+}
+
+func (enc *logfmtEncoder) separate() {
+	if enc.buf.Len() > 0 {
+		enc.buf.AppendByte(' ')
+	}
+}
+
+// addKey 写入key，非法字符替换为下划线
+func (enc *logfmtEncoder) addKey(key string) {
+	enc.separate()
+	if enc.prefix != "" {
+		appendKey(enc.buf, enc.prefix)
+	}
+	appendKey(enc.buf, key)
+	enc.buf.AppendByte('=')
+}
+
+func appendKey(buf *buffer.Buffer, key string) {
+	for i := 0; i < len(key); i++ {
+		if b := key[i]; b <= ' ' || b == '=' || b == '"' || b >= utf8.RuneSelf {
+			appendSanitizedKey(buf, key)
+			return
+		}
+	}
+	buf.AppendString(key)
+}
+
+func appendSanitizedKey(buf *buffer.Buffer, key string) {
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || r == 0x7f {
+			buf.AppendByte('_')
+			continue
+		}
+		buf.AppendString(string(r))
+	}
+}
+
+// appendString 写入字符串值，包含空白、等号、引号、控制字符或为空时加引号并转义
+func (enc *logfmtEncoder) appendString(s string) {
+	appendValue(enc.buf, s)
+}
+
+func appendValue[S ~string | ~[]byte](buf *buffer.Buffer, s S) {
+	if !needsQuote(s) {
+		for i := 0; i < len(s); i++ {
+			buf.AppendByte(s[i])
+		}
+		return
+	}
+	buf.AppendByte('"')
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			switch b {
+			case '"', '\\':
+				buf.AppendByte('\\')
+				buf.AppendByte(b)
+			case '\n':
+				buf.AppendString(`\n`)
+			case '\r':
+				buf.AppendString(`\r`)
+			case '\t':
+				buf.AppendString(`\t`)
+			default:
+				if b < ' ' || b == 0x7f {
+					buf.AppendString(`\u00`)
+					buf.AppendByte(hex[b>>4])
+					buf.AppendByte(hex[b&0xf])
+				} else {
+					buf.AppendByte(b)
+				}
+			}
+			i++
+			continue
+		}
+		r, size := decodeRune(s, i)
+		if r == utf8.RuneError && size == 1 {
+			buf.AppendString("\ufffd")
+		} else {
+			for j := i; j < i+size; j++ {
+				buf.AppendByte(s[j])
+			}
+		}
+		i += size
+	}
+	buf.AppendByte('"')
+}
+
+func needsQuote[S ~string | ~[]byte](s S) bool {
+	if len(s) == 0 {
+		return true
+	}
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			if b <= ' ' || b == '=' || b == '"' || b == '\\' || b == 0x7f {
+				return true
+			}
+			i++
+			continue
+		}
+		r, size := decodeRune(s, i)
+		if r == utf8.RuneError && size == 1 {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+// decodeRune 解码s[i:]开头的字符，复制到栈上的数组以避免[]byte与string转换带来的内存分配
+func decodeRune[S ~string | ~[]byte](s S, i int) (rune, int) {
+	var b [utf8.UTFMax]byte
+	n := copy(b[:], s[i:])
+	return utf8.DecodeRune(b[:n])
+}
+
+// appendEncoded 通过PrimitiveArrayEncoder编码单个值，例如EncodeTime、EncodeLevel的输出
+func (enc *logfmtEncoder) appendEncoded(encode func(zapcore.PrimitiveArrayEncoder)) {
+	arr := getLogfmtArray(nil)
+	defer putLogfmtArray(arr)
+	encode(arr)
+	appendValue(enc.buf, arr.buf.Bytes())
+}
+
+func (enc *logfmtEncoder) AddArray(key string, marshaler zapcore.ArrayMarshaler) error {
+	arr := getLogfmtArray(enc.EncoderConfig)
+	defer putLogfmtArray(arr)
+	arr.buf.AppendByte('[')
+	err := marshaler.MarshalLogArray(arr)
+	arr.buf.AppendByte(']')
+	enc.addKey(key)
+	appendValue(enc.buf, arr.buf.Bytes())
+	return err
+}
+
+func (enc *logfmtEncoder) AddObject(key string, marshaler zapcore.ObjectMarshaler) error {
+	prefix := enc.prefix
+	enc.prefix = prefix + key + "."
+	err := marshaler.MarshalLogObject(enc)
+	enc.prefix = prefix
+	return err
+}
+
+func (enc *logfmtEncoder) AddBinary(key string, value []byte) {
+	enc.AddString(key, base64.StdEncoding.EncodeToString(value))
+}
+
+func (enc *logfmtEncoder) AddByteString(key string, value []byte) {
+	enc.addKey(key)
+	appendValue(enc.buf, value)
+}
+
+func (enc *logfmtEncoder) AddBool(key string, value bool) {
+	enc.addKey(key)
+	enc.buf.AppendBool(value)
+}
+
+func (enc *logfmtEncoder) AddComplex128(key string, value complex128) {
+	enc.addKey(key)
+	appendComplex(enc.buf, value, 64)
+}
+
+func (enc *logfmtEncoder) AddComplex64(key string, value complex64) {
+	enc.addKey(key)
+	appendComplex(enc.buf, complex128(value), 32)
+}
+
+func (enc *logfmtEncoder) AddDuration(key string, value time.Duration) {
+	enc.addKey(key)
+	if enc.EncodeDuration == nil {
+		enc.buf.AppendInt(int64(value))
+		return
+	}
+	enc.appendEncoded(func(arr zapcore.PrimitiveArrayEncoder) { enc.EncodeDuration(value, arr) })
+}
+
+func (enc *logfmtEncoder) AddFloat64(key string, value float64) {
+	enc.addKey(key)
+	appendFloat(enc.buf, value, 64)
+}
+
+func (enc *logfmtEncoder) AddFloat32(key string, value float32) {
+	enc.addKey(key)
+	appendFloat(enc.buf, float64(value), 32)
+}
+
+func (enc *logfmtEncoder) AddInt(key string, value int)     { enc.AddInt64(key, int64(value)) }
+func (enc *logfmtEncoder) AddInt32(key string, value int32) { enc.AddInt64(key, int64(value)) }
+func (enc *logfmtEncoder) AddInt16(key string, value int16) { enc.AddInt64(key, int64(value)) }
+func (enc *logfmtEncoder) AddInt8(key string, value int8)   { enc.AddInt64(key, int64(value)) }
+
+func (enc *logfmtEncoder) AddInt64(key string, value int64) {
+	enc.addKey(key)
+	enc.buf.AppendInt(value)
+}
+
+// AddReflected 按JSON编码，编码结果为对象(如map、struct)时平铺为点号连接的key，其余值输出JSON文本
+func (enc *logfmtEncoder) AddReflected(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if len(data) > 0 && data[0] == '{' {
+		return enc.addJSONObject(key, data)
+	}
+	enc.addKey(key)
+	enc.appendString(string(data))
+	return nil
+}
+
+// addJSONObject 按字段顺序平铺JSON对象，嵌套对象继续平铺，字符串输出原值，数字、布尔、null及数组输出JSON文本
+func (enc *logfmtEncoder) addJSONObject(key string, data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	prefix := enc.prefix
+	enc.prefix = prefix + key + "."
+	defer func() { enc.prefix = prefix }()
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		name, _ := token.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		switch raw[0] {
+		case '{':
+			err = enc.addJSONObject(name, raw)
+		case '"':
+			var s string
+			err = json.Unmarshal(raw, &s)
+			enc.AddString(name, s)
+		default:
+			enc.addKey(name)
+			enc.appendString(string(raw))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (enc *logfmtEncoder) OpenNamespace(key string) {
+	enc.prefix = enc.prefix + key + "."
+}
+
+func (enc *logfmtEncoder) AddString(key, value string) {
+	enc.addKey(key)
+	enc.appendString(value)
+}
+
+func (enc *logfmtEncoder) AddTime(key string, value time.Time) {
+	enc.addKey(key)
+	if enc.EncodeTime == nil {
+		enc.buf.AppendInt(value.UnixNano())
+		return
+	}
+	enc.appendEncoded(func(arr zapcore.PrimitiveArrayEncoder) { enc.EncodeTime(value, arr) })
+}
+
+func (enc *logfmtEncoder) AddUint(key string, value uint)       { enc.AddUint64(key, uint64(value)) }
+func (enc *logfmtEncoder) AddUint32(key string, value uint32)   { enc.AddUint64(key, uint64(value)) }
+func (enc *logfmtEncoder) AddUint16(key string, value uint16)   { enc.AddUint64(key, uint64(value)) }
+func (enc *logfmtEncoder) AddUint8(key string, value uint8)     { enc.AddUint64(key, uint64(value)) }
+func (enc *logfmtEncoder) AddUintptr(key string, value uintptr) { enc.AddUint64(key, uint64(value)) }
+
+func (enc *logfmtEncoder) AddUint64(key string, value uint64) {
+	enc.addKey(key)
+	enc.buf.AppendUint(value)
+}
+
+var logfmtArrayPool = sync.Pool{New: func() interface{} {
+	return &logfmtArray{}
+}}
+
+// logfmtArray 数组及单值编码，元素之间使用逗号分隔，嵌套对象使用{k=v}形式
+type logfmtArray struct {
+	// config 数组元素中时间、时长的编码配置，编码单值时为nil
+	config *zapcore.EncoderConfig
+	buf    *buffer.Buffer
+	count  int
+}
+
+func getLogfmtArray(config *zapcore.EncoderConfig) *logfmtArray {
+	arr := logfmtArrayPool.Get().(*logfmtArray)
+	arr.config = config
+	arr.buf = bufferPool.Get()
+	arr.count = 0
+	return arr
+}
+
+func putLogfmtArray(arr *logfmtArray) {
+	arr.buf.Free()
+	arr.buf = nil
+	arr.config = nil
+	logfmtArrayPool.Put(arr)
+}
+
+func (arr *logfmtArray) separate() {
+	if arr.count > 0 {
+		arr.buf.AppendByte(',')
+	}
+	arr.count++
+}
+
+func (arr *logfmtArray) AppendBool(v bool) {
+	arr.separate()
+	arr.buf.AppendBool(v)
+}
+
+func (arr *logfmtArray) AppendByteString(v []byte) {
+	arr.separate()
+	arr.buf.Write(v)
+}
+
+func (arr *logfmtArray) AppendComplex128(v complex128) {
+	arr.separate()
+	appendComplex(arr.buf, v, 64)
+}
+
+func (arr *logfmtArray) AppendComplex64(v complex64) {
+	arr.separate()
+	appendComplex(arr.buf, complex128(v), 32)
+}
+
+func (arr *logfmtArray) AppendFloat64(v float64) {
+	arr.separate()
+	appendFloat(arr.buf, v, 64)
+}
+
+func (arr *logfmtArray) AppendFloat32(v float32) {
+	arr.separate()
+	appendFloat(arr.buf, float64(v), 32)
+}
+
+func (arr *logfmtArray) AppendInt(v int)     { arr.AppendInt64(int64(v)) }
+func (arr *logfmtArray) AppendInt32(v int32) { arr.AppendInt64(int64(v)) }
+func (arr *logfmtArray) AppendInt16(v int16) { arr.AppendInt64(int64(v)) }
+func (arr *logfmtArray) AppendInt8(v int8)   { arr.AppendInt64(int64(v)) }
+
+func (arr *logfmtArray) AppendInt64(v int64) {
+	arr.separate()
+	arr.buf.AppendInt(v)
+}
+
+func (arr *logfmtArray) AppendString(v string) {
+	arr.separate()
+	arr.buf.AppendString(v)
+}
+
+func (arr *logfmtArray) AppendUint(v uint)       { arr.AppendUint64(uint64(v)) }
+func (arr *logfmtArray) AppendUint32(v uint32)   { arr.AppendUint64(uint64(v)) }
+func (arr *logfmtArray) AppendUint16(v uint16)   { arr.AppendUint64(uint64(v)) }
+func (arr *logfmtArray) AppendUint8(v uint8)     { arr.AppendUint64(uint64(v)) }
+func (arr *logfmtArray) AppendUintptr(v uintptr) { arr.AppendUint64(uint64(v)) }
+
+func (arr *logfmtArray) AppendUint64(v uint64) {
+	arr.separate()
+	arr.buf.AppendUint(v)
+}
+
+// AppendDuration 按EncodeDuration编码，未配置或未输出内容时输出纳秒数
+func (arr *logfmtArray) AppendDuration(v time.Duration) {
+	cur := arr.buf.Len()
+	if arr.config != nil && arr.config.EncodeDuration != nil {
+		arr.config.EncodeDuration(v, arr)
+	}
+	if cur == arr.buf.Len() {
+		arr.AppendInt64(int64(v))
+	}
+}
+
+// AppendTime 按EncodeTime编码，未配置或未输出内容时使用RFC3339Nano格式
+func (arr *logfmtArray) AppendTime(v time.Time) {
+	cur := arr.buf.Len()
+	if arr.config != nil && arr.config.EncodeTime != nil {
+		arr.config.EncodeTime(v, arr)
+	}
+	if cur == arr.buf.Len() {
+		arr.separate()
+		arr.buf.AppendTime(v, time.RFC3339Nano)
+	}
+}
+
+func (arr *logfmtArray) AppendArray(marshaler zapcore.ArrayMarshaler) error {
+	arr.separate()
+	nested := getLogfmtArray(arr.config)
+	defer putLogfmtArray(nested)
+	err := marshaler.MarshalLogArray(nested)
+	arr.buf.AppendByte('[')
+	arr.buf.Write(nested.buf.Bytes())
+	arr.buf.AppendByte(']')
+	return err
+}
+
+func (arr *logfmtArray) AppendObject(marshaler zapcore.ObjectMarshaler) error {
+	arr.separate()
+	config := arr.config
+	if config == nil {
+		config = &zapcore.EncoderConfig{}
+	}
+	enc := getLogfmtEncoder(config, "")
+	defer putLogfmtEncoder(enc)
+	err := marshaler.MarshalLogObject(enc)
+	arr.buf.AppendByte('{')
+	arr.buf.Write(enc.buf.Bytes())
+	arr.buf.AppendByte('}')
+	enc.buf.Free()
+	return err
+}
+
+func (arr *logfmtArray) AppendReflected(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	arr.separate()
+	arr.buf.Write(data)
+	return nil
+}
+
+func appendFloat(buf *buffer.Buffer, v float64, bitSize int) {
+	switch {
+	case math.IsNaN(v):
+		buf.AppendString("NaN")
+	case math.IsInf(v, 1):
+		buf.AppendString("+Inf")
+	case math.IsInf(v, -1):
+		buf.AppendString("-Inf")
+	default:
+		buf.AppendFloat(v, bitSize)
+	}
+}
+
+func appendComplex(buf *buffer.Buffer, v complex128, bitSize int) {
+	appendFloat(buf, real(v), bitSize)
+	if i := imag(v); i >= 0 && !math.IsInf(i, 1) {
+		buf.AppendByte('+')
+	}
+	appendFloat(buf, imag(v), bitSize)
+	buf.AppendByte('i')
+}
@@ -0,0 +1,142 @@
+package log
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type testUser struct {
+	Name string
+	Tags []string
+}
+
+func (u testUser) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", u.Name)
+	return enc.AddArray("tags", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		for _, tag := range u.Tags {
+			arr.AppendString(tag)
+		}
+		return nil
+	}))
+}
+
+func testEntry() zapcore.Entry {
+	return zapcore.Entry{
+		Level:      zapcore.InfoLevel,
+		Time:       time.Date(2026, 10, 17, 8, 30, 0, 0, time.UTC),
+		LoggerName: "orders",
+		Message:    "order created",
+	}
+}
+
+func TestLogfmtEncoder(t *testing.T) {
+	config := presetEncoderConfig(PresetLogfmtFriendly)
+	enc := NewLogfmtEncoder(config)
+	enc.AddString("service", "billing")
+	enc.OpenNamespace("req")
+
+	buf, err := enc.EncodeEntry(testEntry(), []zapcore.Field{
+		zap.String("path", "/v1/orders"),
+		zap.String("query", `a="b c"`),
+		zap.String("empty", ""),
+		zap.String("multi\nline key", "line1\nline2\t\x01"),
+		zap.Int("status", 201),
+		zap.Duration("took", 1500*time.Millisecond),
+		zap.Object("user", testUser{Name: "张三", Tags: []string{"vip", "new user"}}),
+		zap.Error(errors.New("boom")),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `ts=2026-10-17T08:30:00Z level=info logger=orders msg="order created" service=billing ` +
+		`req.path=/v1/orders req.query="a=\"b c\"" req.empty="" req.multi_line_key="line1\nline2\t\u0001" ` +
+		`req.status=201 req.took=1.5s req.user.name=张三 req.user.tags="[vip,new user]" req.error=boom` + "\n"
+	if buf.String() != want {
+		t.Fatalf("logfmt encoding mismatch\n got: %s\nwant: %s", buf.String(), want)
+	}
+	buf.Free()
+}
+
+func TestLogfmtFormat(t *testing.T) {
+	encoder := newEncoder(ConfigBase{Format: FormatLogfmt}, SinkConfig{})
+	buf, err := encoder.EncodeEntry(testEntry(), []zapcore.Field{zap.Bool("ok", true)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "time=2026-10-17T08:30:00.000Z level=INFO logger=orders") {
+		t.Fatalf("unexpected logfmt output %q", buf.String())
+	}
+}
+
+func TestLogfmtReflectedAndArrays(t *testing.T) {
+	type address struct {
+		City string `json:"city"`
+		Zip  int    `json:"zip"`
+	}
+	type profile struct {
+		Name    string            `json:"name"`
+		Address address           `json:"address"`
+		Tags    []string          `json:"tags"`
+		Labels  map[string]string `json:"labels"`
+	}
+	config := presetEncoderConfig(PresetLogfmtFriendly)
+	config.EncodeTime = zapcore.TimeEncoderOfLayout(time.DateOnly)
+	config.EncodeDuration = zapcore.MillisDurationEncoder
+	enc := NewLogfmtEncoder(config)
+	day := time.Date(2026, 10, 17, 8, 30, 0, 0, time.UTC)
+	buf, err := enc.EncodeEntry(zapcore.Entry{Message: "reflected"}, []zapcore.Field{
+		zap.Any("profile", profile{Name: "bob smith", Address: address{City: "上海", Zip: 200000}, Tags: []string{"a"}, Labels: map[string]string{"b": "2", "a": "1"}}),
+		zap.Any("meta", map[string]any{"ok": true, "none": nil}),
+		zap.Any("ids", []int{1, 2}),
+		zap.Times("days", []time.Time{day, day.AddDate(0, 0, 1)}),
+		zap.Durations("took", []time.Duration{1500 * time.Millisecond}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `level=info msg=reflected profile.name="bob smith" profile.address.city=上海 profile.address.zip=200000 ` +
+		`profile.tags="[\"a\"]" profile.labels.a=1 profile.labels.b=2 meta.none=null meta.ok=true ids=[1,2] ` +
+		`days=[2026-10-17,2026-10-18] took=[1500]` + "\n"
+	if buf.String() != want {
+		t.Fatalf("logfmt encoding mismatch\n got: %s\nwant: %s", buf.String(), want)
+	}
+	buf.Free()
+}
+
+func benchmarkFields() []zapcore.Field {
+	return []zapcore.Field{
+		zap.String("path", "/v1/orders"),
+		zap.String("query", "page=1&size=20"),
+		zap.Int("status", 200),
+		zap.Duration("took", 15*time.Millisecond),
+		zap.Bool("cached", false),
+		zap.Object("user", testUser{Name: "alice", Tags: []string{"vip"}}),
+	}
+}
+
+func benchmarkEncoder(b *testing.B, enc zapcore.Encoder) {
+	entry := testEntry()
+	fields := benchmarkFields()
+	enc.AddString("service", "billing")
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			buf, _ := enc.EncodeEntry(entry, fields)
+			buf.Free()
+		}
+	})
+}
+
+func BenchmarkLogfmtEncoder(b *testing.B) {
+	benchmarkEncoder(b, NewLogfmtEncoder(getEncoderConfig()))
+}
+
+func BenchmarkJSONEncoder(b *testing.B) {
+	benchmarkEncoder(b, zapcore.NewJSONEncoder(getEncoderConfig()))
+}
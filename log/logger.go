@@ -2,10 +2,16 @@ package log
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
 	"os"
+	"reflect"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -15,8 +21,19 @@ var (
 	loggers    = make(map[string]*Logger)
 	global     *Logger
 	initialize bool
+
+	globalState  = newLoggerState()
+	globalConfig GlobalConfig
+	childStates  = make(map[string]*loggerState)
+	childConfigs = make(map[string]ChildConfig)
+	files        = make(map[string]rotatingFile)
+	asyncWriters []*asyncWriter
+	// rateLimiters 当前core使用的限流器，core被替换时停止其汇总协程
+	rateLimiters []*rateLimiter
 )
 
+var levelNames = []string{"debug", "info", "warn", "error", "dpanic", "panic", "fatal"}
+
 var levelMap = map[string]zapcore.Level{
 	"debug":  zapcore.DebugLevel,
 	"info":   zapcore.InfoLevel,
@@ -27,117 +44,361 @@ var levelMap = map[string]zapcore.Level{
 	"fatal":  zapcore.FatalLevel,
 }
 
+// GlobalConfig 全局logger配置，配置Sinks或Outputs后按Sinks及Outputs输出，EnableFileLog及文件配置不再生效
 type GlobalConfig struct {
-	Level         string
-	EnableFileLog bool
-	ConfigBase
-	FileLogConfig
+	Level         string         `yaml:"level" json:"level" mapstructure:"level" validate:"omitempty,loglevel"`
+	EnableFileLog bool           `yaml:"enable_file_log" json:"enable_file_log" mapstructure:"enable_file_log"`
+	Sinks         []SinkConfig   `yaml:"sinks" json:"sinks" mapstructure:"sinks" validate:"dive"`
+	Outputs       []OutputConfig `yaml:"outputs" json:"outputs" mapstructure:"outputs" validate:"dive"`
+	// LevelRules 子logger的级别规则，如["db.*=warn", "db.pool=debug"]，见SetLevelRules
+	LevelRules    []string `yaml:"level_rules" json:"level_rules" mapstructure:"level_rules"`
+	ConfigBase    `yaml:",inline" mapstructure:",squash"`
+	FileLogConfig `yaml:",inline" mapstructure:",squash"`
 }
 
+// ChildConfig 子logger配置，Sinks及Outputs的含义与GlobalConfig一致
 type ChildConfig struct {
-	LoggerName    string
-	Level         string
-	EnableFileLog bool
-	ConfigBase
-	FileLogConfig
+	LoggerName string `yaml:"logger_name" json:"logger_name" mapstructure:"logger_name" validate:"required"`
+	// Inherit 未设置的字段继承最近的已配置祖先(按名称中的"."划分层级)或全局配置，零值视为未设置，
+	// 需要将继承的字段设置为false或0(如show_line_number、compress、max_age)时将其列在NoInherit中
+	Inherit bool `yaml:"inherit" json:"inherit" mapstructure:"inherit"`
+	// NoInherit 开启Inherit时不继承的字段，使用配置文件中的名称，如["show_line_number", "max_age"]，
+	// 支持level及格式、文件相关的字段，列出的字段按子logger自身的值(包括零值)生效
+	NoInherit     []string       `yaml:"no_inherit" json:"no_inherit" mapstructure:"no_inherit"`
+	Level         string         `yaml:"level" json:"level" mapstructure:"level" validate:"omitempty,loglevel"`
+	EnableFileLog bool           `yaml:"enable_file_log" json:"enable_file_log" mapstructure:"enable_file_log"`
+	Sinks         []SinkConfig   `yaml:"sinks" json:"sinks" mapstructure:"sinks" validate:"dive"`
+	Outputs       []OutputConfig `yaml:"outputs" json:"outputs" mapstructure:"outputs" validate:"dive"`
+	ConfigBase    `yaml:",inline" mapstructure:",squash"`
+	FileLogConfig `yaml:",inline" mapstructure:",squash"`
 }
 
+// ConfigBase 输出格式配置，Format为空时按JSONFormat选择json或console格式
 type ConfigBase struct {
-	JSONFormat     bool
-	ShowLineNumber bool
+	Format         Format        `yaml:"format" json:"format" mapstructure:"format" validate:"omitempty,oneof=json console logfmt"`
+	JSONFormat     bool          `yaml:"json_format" json:"json_format" mapstructure:"json_format"`
+	ShowLineNumber bool          `yaml:"show_line_number" json:"show_line_number" mapstructure:"show_line_number"`
+	Encoder        EncoderConfig `yaml:"encoder" json:"encoder" mapstructure:"encoder"`
+	// Sampling 采样配置，作用于该logger的全部输出
+	Sampling SamplingConfig `yaml:"sampling" json:"sampling" mapstructure:"sampling"`
+	// RateLimit 按消息限流配置，作用于该logger的全部输出
+	RateLimit RateLimitConfig `yaml:"rate_limit" json:"rate_limit" mapstructure:"rate_limit"`
+	// Async 异步写入配置，作用于该logger的全部字节流输出
+	Async AsyncConfig `yaml:"async" json:"async" mapstructure:"async"`
+	// Redact 敏感信息脱敏配置，作用于该logger的全部输出
+	Redact RedactConfig `yaml:"redact" json:"redact" mapstructure:"redact"`
 }
 
 type FileLogConfig struct {
-	FileName   string
-	MaxSize    int
-	MaxBackups int
-	MaxAge     int
-	Compress   bool
+	FileName   string `yaml:"file_name" json:"file_name" mapstructure:"file_name"`
+	MaxSize    int    `yaml:"max_size" json:"max_size" mapstructure:"max_size" validate:"min=0"`
+	MaxBackups int    `yaml:"max_backups" json:"max_backups" mapstructure:"max_backups" validate:"min=0"`
+	MaxAge     int    `yaml:"max_age" json:"max_age" mapstructure:"max_age" validate:"min=0"`
+	Compress   bool   `yaml:"compress" json:"compress" mapstructure:"compress"`
+	// Rotation 切割策略，size、daily、hourly或size+time，默认size；按时间切割时MaxBackups、MaxAge同样用于清理旧文件
+	Rotation string `yaml:"rotation" json:"rotation" mapstructure:"rotation" validate:"omitempty,oneof=size daily hourly size+time"`
+	// FilePattern 按时间切割时的文件名模板，支持%Y、%m、%d、%H、%i，默认在FileName的扩展名前加上日期
+	FilePattern string `yaml:"file_pattern" json:"file_pattern" mapstructure:"file_pattern"`
+	// UTC 按UTC时间划分时间段及生成文件名，默认使用本地时间
+	UTC bool `yaml:"utc" json:"utc" mapstructure:"utc"`
+	// Compression 切割出的文件的压缩算法，gzip或zstd，为空时按Compress决定是否使用gzip
+	Compression string `yaml:"compression" json:"compression" mapstructure:"compression" validate:"omitempty,oneof=gzip zstd"`
+	// Checksum 为切割出的文件生成sha256校验和文件
+	Checksum bool `yaml:"checksum" json:"checksum" mapstructure:"checksum"`
+	// ArchiveDir 切割出的文件移动到的归档目录，清理旧文件时同样计算在内
+	ArchiveDir string `yaml:"archive_dir" json:"archive_dir" mapstructure:"archive_dir"`
+	// Upload 切割出的文件上传到的对象存储，需先通过RegisterObjectStore注册
+	Upload string `yaml:"upload" json:"upload" mapstructure:"upload"`
+	// UploadPrefix 上传时对象名称的前缀
+	UploadPrefix string `yaml:"upload_prefix" json:"upload_prefix" mapstructure:"upload_prefix"`
 }
 
 type Logger struct {
-	l *zap.Logger
+	// l 记录调用位置，plain 不记录调用位置，按caller选择
+	l      *zap.Logger
+	plain  *zap.Logger
+	caller *atomic.Bool
+	// s、plainS 分别对应l及plain，首次使用printf及键值对形式的方法时创建
+	sugarOnce sync.Once
+	s         *zap.SugaredLogger
+	plainS    *zap.SugaredLogger
+}
+
+// wrapLogger 创建Logger，l需已开启AddCaller并通过AddCallerSkip跳过Logger方法所在的栈帧，
+// caller为对应loggerState的caller
+func wrapLogger(l *zap.Logger, caller *atomic.Bool) *Logger {
+	return &Logger{l: l, plain: l.WithOptions(zap.WithCaller(false)), caller: caller}
+}
+
+// zapLogger 返回按当前配置决定是否记录调用位置的zap.Logger
+func (log *Logger) zapLogger() *zap.Logger {
+	if log.caller.Load() {
+		return log.l
+	}
+	return log.plain
+}
+
+// sugar 返回按当前配置决定是否记录调用位置的SugaredLogger
+func (log *Logger) sugar() *zap.SugaredLogger {
+	log.sugarOnce.Do(func() {
+		log.s, log.plainS = log.l.Sugar(), log.plain.Sugar()
+	})
+	if log.caller.Load() {
+		return log.s
+	}
+	return log.plainS
 }
 
 // InitLogger is init global logger
+//
+// InitLogger 不校验配置，无法识别的日志级别按info处理，需要在启动时发现配置错误请使用InitLoggerE
 func InitLogger(config GlobalConfig, childConfig ...ChildConfig) {
-	logLock.Lock()
-	defer logLock.Unlock()
+	reportRelease(initLoggerLocked(config, childConfig))
+}
+
+func initLoggerLocked(config GlobalConfig, childConfig []ChildConfig) error {
+	return withLogLock(func(retired *retiredSinks) error {
+		applyConfig(retired, config, childConfig, nil)
+		return nil
+	})
+}
+
+// reloadLogger 校验配置后重新初始化，同时移除removed中仍存在的子logger，用于配置文件中删除了子logger的情况
+func reloadLogger(config FileConfig, removed []string) error {
+	if err := validateConfig(config, nil); err != nil {
+		return err
+	}
+	return withLogLock(func(retired *retiredSinks) error {
+		applyConfig(retired, config.Global, config.Children, removed)
+		return nil
+	})
+}
+
+// applyConfig 应用全局及子logger配置，调用方需持有logLock
+func applyConfig(retired *retiredSinks, config GlobalConfig, children []ChildConfig, removed []string) {
+	for _, name := range removed {
+		if _, ok := childConfigs[name]; ok {
+			removeChildLogger(retired, name)
+		}
+	}
 	initLogger(config)
 	initialize = true
-	initChildLoggers(childConfig...)
+	refreshLevels(initChildLoggers(retired, children...))
+	rebuildCores(retired)
+}
+
+// InitLoggerE 校验配置后初始化全局logger，配置非法时不做任何修改并返回包含全部非法字段的*ConfigError；
+// 配置生效后同步或关闭被替换的输出失败时同样返回错误
+func InitLoggerE(config GlobalConfig, childConfig ...ChildConfig) error {
+	if err := validateConfig(FileConfig{Global: config, Children: childConfig}, nil); err != nil {
+		return err
+	}
+	return initLoggerLocked(config, childConfig)
 }
 
 // AddChildLogger 添加子logger对象
 func AddChildLogger(config ...ChildConfig) {
-	logLock.Lock()
-	defer logLock.Unlock()
-	if initialize {
-		initChildLoggers(config...)
-		return
+	reportRelease(withLogLock(func(retired *retiredSinks) error {
+		if !initialize {
+			panic("your should init global logger first! Please call InitLogger() first")
+		}
+		refreshLevels(initChildLoggers(retired, config...))
+		rebuildCores(retired)
+		return nil
+	}))
+}
+
+// AddChildLoggerE 校验配置后添加子logger，名称与已有子logger重复时同样视为配置错误
+func AddChildLoggerE(config ...ChildConfig) error {
+	return withLogLock(func(retired *retiredSinks) error {
+		if !initialize {
+			return errors.New("log: global logger is not initialized, call InitLogger first")
+		}
+		if err := validateConfig(FileConfig{Children: config}, childConfigs); err != nil {
+			return err
+		}
+		refreshLevels(initChildLoggers(retired, config...))
+		rebuildCores(retired)
+		return nil
+	})
+}
+
+// ReplaceChildLogger 校验配置后替换已存在的子logger，替换后同步旧的输出并关闭不再使用的文件；
+// core原子替换，已获取的Logger无需重新获取，替换过程中写入的日志不会丢失；同步或关闭旧的输出失败时配置仍然生效并返回错误
+func ReplaceChildLogger(config ChildConfig) error {
+	return withLogLock(func(retired *retiredSinks) error {
+		if _, ok := childConfigs[config.LoggerName]; !ok {
+			return fmt.Errorf("log: unknown logger %q", config.LoggerName)
+		}
+		existing := make(map[string]ChildConfig, len(childConfigs))
+		for name, child := range childConfigs {
+			if name != config.LoggerName {
+				existing[name] = child
+			}
+		}
+		if err := validateConfig(FileConfig{Children: []ChildConfig{config}}, existing); err != nil {
+			return err
+		}
+		refreshLevels(initChildLoggers(retired, config))
+		rebuildCores(retired)
+		return nil
+	})
+}
+
+// RemoveChildLogger 移除子logger，包括通过Get自动创建的logger，移除后同步其输出并关闭不再使用的文件；
+// 已获取的Logger继续可用，此后按全局logger的级别及输出写入；同步或关闭旧的输出失败时同样已移除并返回错误
+func RemoveChildLogger(name string) error {
+	return withLogLock(func(retired *retiredSinks) error {
+		if _, ok := childStates[name]; !ok {
+			return fmt.Errorf("log: unknown logger %q", name)
+		}
+		removeChildLogger(retired, name)
+		// 继承该logger配置的后代改为继承更上层的配置
+		refreshLevels(nil)
+		rebuildCores(retired)
+		return nil
+	})
+}
+
+// removeChildLogger 移除子logger，已获取的Logger改为委托给全局logger，调用方需持有logLock
+func removeChildLogger(retired *retiredSinks, name string) {
+	state := childStates[name]
+	retired.cores = append(retired.cores, state.core.Load())
+	state.core.Store(&swapCore{ref: &globalState.core})
+	// 此后按全局logger的配置输出，无法跟随全局配置的变化，始终记录调用位置
+	state.caller.Store(true)
+	delete(childStates, name)
+	delete(childConfigs, name)
+	delete(loggers, name)
+	delete(autoLoggers, name)
+}
+
+// ListChildLoggers 返回已配置的子logger的配置，按名称排序，不包括通过Get自动创建的logger
+func ListChildLoggers() []ChildConfig {
+	logLock.RLock()
+	defer logLock.RUnlock()
+	configs := make([]ChildConfig, 0, len(childConfigs))
+	for _, config := range childConfigs {
+		configs = append(configs, config)
 	}
-	panic("your should init global logger first! Please call InitLogger() first")
+	sort.Slice(configs, func(i, j int) bool { return configs[i].LoggerName < configs[j].LoggerName })
+	return configs
 }
 
 // initLogger 初始化logger
 func initLogger(config GlobalConfig) {
-	logLevel := getLogLevel(config.Level)
-	encoderConfig := getEncoderConfig()
-	var encoder zapcore.Encoder
-	if config.JSONFormat {
-		encoder = zapcore.NewJSONEncoder(encoderConfig)
-	} else {
-		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	globalConfig = config
+	// 配置的级别未变化时保留运行时通过SetLevel调整的级别
+	if source := (levelSource{level: getLogLevel(config.Level)}); globalState.levelSource == nil || *globalState.levelSource != source {
+		globalState.applyLevel(source)
 	}
-
-	var core zapcore.Core
-	if config.EnableFileLog {
-		hook := getHooks(config.FileName, config.MaxSize, config.MaxBackups, config.MaxAge, config.Compress)
-		core = zapcore.NewCore(encoder, zapcore.NewMultiWriteSyncer(zapcore.AddSync(os.Stdout), zapcore.AddSync(&hook)), logLevel)
-	} else {
-		core = zapcore.NewCore(encoder, zapcore.NewMultiWriteSyncer(zapcore.AddSync(os.Stdout)), logLevel)
-	}
-
-	zapLogger := zap.New(core)
-	if config.ShowLineNumber {
-		zapLogger = zapLogger.WithOptions(zap.AddCaller(), zap.AddCallerSkip(1))
+	levelRules, _ = parseLevelRules(config.LevelRules, true)
+	if !initialize {
+		zap.ReplaceGlobals(global.l)
 	}
-	zap.ReplaceGlobals(zapLogger)
-	global = &Logger{zapLogger}
 }
 
-// initChildLoggers 初始化子logger集合
-func initChildLoggers(childs ...ChildConfig) {
+// initChildLoggers 初始化子logger集合，返回新增或配置发生变化的logger名称；同名的已有logger原地更新，已获取的Logger继续有效
+func initChildLoggers(retired *retiredSinks, childs ...ChildConfig) map[string]bool {
+	changed := make(map[string]bool, len(childs))
 	for _, config := range childs {
-		logLevel := getLogLevel(config.Level)
-		encoderConfig := getEncoderConfig()
-		var encoder zapcore.Encoder
-		if config.JSONFormat {
-			encoder = zapcore.NewJSONEncoder(encoderConfig)
+		previous, existed := childConfigs[config.LoggerName]
+		changed[config.LoggerName] = !existed || !reflect.DeepEqual(previous, config)
+		childConfigs[config.LoggerName] = config
+		delete(autoLoggers, config.LoggerName)
+		if state, ok := childStates[config.LoggerName]; ok {
+			// 旧的输出在释放logLock后同步，旧的异步写入及文件随后停止并关闭
+			retired.cores = append(retired.cores, state.core.Load())
 		} else {
-			encoder = zapcore.NewConsoleEncoder(encoderConfig)
+			state := newLoggerState()
+			childStates[config.LoggerName] = state
+			loggers[config.LoggerName] = state.newLogger().Named(config.LoggerName)
 		}
+	}
+	return changed
+}
 
-		var childCore zapcore.Core
-		if config.EnableFileLog {
-			hook := getHooks(config.FileName, config.MaxSize, config.MaxBackups, config.MaxAge, config.Compress)
-			childCore = zapcore.NewCore(encoder, zapcore.NewMultiWriteSyncer(zapcore.AddSync(os.Stdout), zapcore.AddSync(&hook)), logLevel)
-		} else {
-			childCore = zapcore.NewCore(encoder, zapcore.NewMultiWriteSyncer(zapcore.AddSync(os.Stdout)), logLevel)
+// rebuildCores 根据当前配置重建所有logger的core并原子替换，未变化的日志文件继续复用，
+// 旧的异步写入及不再使用的文件记录到retired，释放logLock后停止并关闭
+func rebuildCores(retired *retiredSinks) {
+	opened := &openedSinks{files: make(map[string]rotatingFile, len(files))}
+
+	globalCore := buildCore(globalConfig.coreConfig(), globalState, opened)
+	childCores := make(map[string]zapcore.Core, len(childConfigs))
+	for name, config := range effectiveChildConfigs() {
+		childCores[name] = buildCore(config.coreConfig(), childStates[name], opened)
+	}
+
+	globalState.core.Store(globalCore)
+	for name, core := range childCores {
+		childStates[name].core.Store(core)
+	}
+
+	retired.async = append(retired.async, asyncWriters...)
+	asyncWriters = opened.async
+	retired.limiters = append(retired.limiters, rateLimiters...)
+	rateLimiters = opened.limiters
+	for fileName, hook := range files {
+		if opened.files[fileName] != hook {
+			retired.files = append(retired.files, hook)
 		}
+	}
+	files = opened.files
+}
 
-		child := zap.L().Named(config.LoggerName)
-		child = child.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
-			return childCore
-		}))
-		if config.ShowLineNumber {
-			child = child.WithOptions(zap.AddCaller(), zap.AddCallerSkip(1))
+// retiredSinks 被替换的core及不再使用的异步写入、文件；输出可能阻塞，需在释放logLock后处理，
+// 否则阻塞期间全部logger的获取及配置都会等待
+type retiredSinks struct {
+	cores    []zapcore.Core
+	async    []*asyncWriter
+	files    []rotatingFile
+	limiters []*rateLimiter
+}
+
+// release 依次停止限流器(输出剩余的汇总)、同步core、停止异步写入(写出剩余日志)并关闭文件
+func (r *retiredSinks) release() error {
+	for _, l := range r.limiters {
+		l.stop()
+	}
+	err := syncAll(r.cores)
+	for _, w := range r.async {
+		w.stop()
+	}
+	for _, hook := range r.files {
+		err = errors.Join(err, hook.Close())
+	}
+	return err
+}
+
+// withLogLock 持有logLock执行fn，释放logLock后再处理fn中被替换的输出
+func withLogLock(fn func(retired *retiredSinks) error) (err error) {
+	retired := &retiredSinks{}
+	defer func() {
+		if releaseErr := retired.release(); releaseErr != nil {
+			err = errors.Join(err, fmt.Errorf("log: release replaced sinks: %w", releaseErr))
 		}
-		loggers[config.LoggerName] = &Logger{child}
+	}()
+	logLock.Lock()
+	defer logLock.Unlock()
+	return fn(retired)
+}
+
+// reportRelease 没有返回值的函数将同步及关闭旧输出的错误输出到标准错误
+func reportRelease(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v %v\n", time.Now(), err)
+	}
+}
+
+// ParseLevel 严格解析日志级别，仅接受debug、info、warn、error、dpanic、panic、fatal
+func ParseLevel(level string) (zapcore.Level, error) {
+	if zapLevel, ok := levelMap[level]; ok {
+		return zapLevel, nil
 	}
+	return zapcore.InfoLevel, fmt.Errorf("log: unknown level %q, must be one of %s", level, strings.Join(levelNames, ", "))
 }
 
-// getLogLevel 日志级别映射
+// getLogLevel 日志级别映射，无法识别的级别按info处理，仅用于未经校验的InitLogger、AddChildLogger
 func getLogLevel(level string) zapcore.Level {
 	if zapLevel, ok := levelMap[level]; ok {
 		return zapLevel
@@ -145,63 +406,50 @@ func getLogLevel(level string) zapcore.Level {
 	return zapcore.InfoLevel
 }
 
-// getEncoderConfig 设置zap输出格式内容
-func getEncoderConfig() zapcore.EncoderConfig {
-	return zapcore.EncoderConfig{
-		TimeKey:        "time",
-		LevelKey:       "level",
-		NameKey:        "logger",
-		CallerKey:      "caller",
-		MessageKey:     "msg",
-		StacktraceKey:  "stacktrace",
-		LineEnding:     zapcore.DefaultLineEnding,
-		EncodeLevel:    zapcore.CapitalLevelEncoder,
-		EncodeTime:     zapcore.ISO8601TimeEncoder,
-		EncodeDuration: zapcore.SecondsDurationEncoder,
-		EncodeCaller:   zapcore.ShortCallerEncoder,
-		EncodeName:     zapcore.FullNameEncoder,
+// getHooks 设置日志文件切割规则，同一文件且切割规则未变化时复用已打开的文件
+func getHooks(config FileLogConfig, opened map[string]rotatingFile) rotatingFile {
+	if hook, ok := opened[config.FileName]; ok {
+		return hook
 	}
-}
-
-// getHooks 设置日志文件切割规则
-func getHooks(fileName string, maxSize, maxBackups, maxAge int, compress bool) lumberjack.Logger {
-	return lumberjack.Logger{
-		Filename:   fileName,   // 日志文件路径
-		MaxSize:    maxSize,    // megabytes
-		MaxBackups: maxBackups, // 最多保留300个备份
-		Compress:   compress,   // 是否压缩 disabled by default
-		MaxAge:     maxAge,
+	hook, ok := files[config.FileName]
+	if !ok || !reusable(hook, config) {
+		hook = newRotatingFile(config)
 	}
+	opened[config.FileName] = hook
+	return hook
 }
 
-// GetLogger 获取全局Logger
+// GetLogger 获取全局Logger，不会返回nil，调用InitLogger之前输出到默认logger
 func GetLogger() *Logger {
 	logLock.RLock()
 	defer logLock.RUnlock()
 	return global
 }
 
-// GetLoggerWithFileName 根据自定义时定义的日志文件名获取日志Logger
+// GetLoggerWithFileName 根据自定义时定义的日志文件名获取日志Logger，不存在时返回nil，需要始终返回Logger时使用Get
 func GetLoggerWithFileName(name string) *Logger {
 	logLock.RLock()
 	defer logLock.RUnlock()
 	return loggers[name]
 }
 
-const ctxKey = "log_with_context"
+type ctxKey struct{}
 
 // SetContextLogger 将logger通过context上下文进行传递，主要用于协程中特殊logger配置相关场景
 func SetContextLogger(ctx context.Context, logger *Logger) context.Context {
-	return context.WithValue(ctx, ctxKey, logger)
+	return context.WithValue(ctx, ctxKey{}, logger)
 }
 
 // GetContextLogger 从context上下文中获取logger对象
 func GetContextLogger(ctx context.Context) *Logger {
-	log, ok := ctx.Value(ctxKey).(*Logger)
+	if ctx == nil {
+		return GetLogger()
+	}
+	log, ok := ctx.Value(ctxKey{}).(*Logger)
 	if ok {
 		return log
 	}
-	return global
+	return GetLogger()
 }
 
 // GetLoggerWitchCtx 从context上下文中获取logger对象
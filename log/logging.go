@@ -58,86 +58,86 @@ var (
 	Err         = zap.Error
 )
 
+// Named 返回全局logger派生的新Logger，不影响全局logger
 func Named(s string) *Logger {
-	l := &Logger{
-		l: GetLogger().l,
-	}
-	return l.Named(s)
+	return GetLogger().Named(s)
 }
 
+// With 返回全局logger附加字段后的新Logger，不影响全局logger
 func With(fields ...Field) *Logger {
-	l := &Logger{
-		l: GetLogger().l,
-	}
-	return l.With(fields...)
+	return GetLogger().With(fields...)
 }
 
+// 包级别函数直接调用zap.Logger，与Logger的方法调用栈深度相同，保证调用位置指向调用方
+
 func Debug(msg string, fields ...Field) {
-	GetLogger().Debug(msg, fields...)
+	GetLogger().zapLogger().Debug(msg, fields...)
 }
 
 func Info(msg string, fields ...Field) {
-	GetLogger().Info(msg, fields...)
+	GetLogger().zapLogger().Info(msg, fields...)
 }
 
 func Warn(msg string, fields ...Field) {
-	GetLogger().Warn(msg, fields...)
+	GetLogger().zapLogger().Warn(msg, fields...)
 }
 
 func Error(msg string, fields ...Field) {
-	GetLogger().Error(msg, fields...)
+	GetLogger().zapLogger().Error(msg, fields...)
 }
 
 func DPanic(msg string, fields ...Field) {
-	GetLogger().DPanic(msg, fields...)
+	GetLogger().zapLogger().DPanic(msg, fields...)
 }
 
 func Panic(msg string, fields ...Field) {
-	GetLogger().Panic(msg, fields...)
+	GetLogger().zapLogger().Panic(msg, fields...)
 }
 
 func Fatal(msg string, fields ...Field) {
-	GetLogger().Fatal(msg, fields...)
+	GetLogger().zapLogger().Fatal(msg, fields...)
 }
 
+// Named 返回名称追加s的新Logger，接收者不变，可在多个协程中并发调用
 func (log *Logger) Named(s string) *Logger {
-	log.l = log.l.Named(s)
-	return log
+	if s == "" {
+		return log
+	}
+	return wrapLogger(log.l.Named(s), log.caller)
 }
 
+// With 返回附加字段的新Logger，接收者不变，可在多个协程中并发调用
 func (log *Logger) With(fields ...Field) *Logger {
 	if len(fields) == 0 {
 		return log
 	}
-
-	log.l = log.l.With(fields...)
-	return log
+	return wrapLogger(log.l.With(fields...), log.caller)
 }
 
 func (log *Logger) Debug(msg string, fields ...Field) {
-	log.l.Debug(msg, fields...)
+	log.zapLogger().Debug(msg, fields...)
 }
 
 func (log *Logger) Info(msg string, fields ...Field) {
-	log.l.Info(msg, fields...)
+	log.zapLogger().Info(msg, fields...)
 }
 
 func (log *Logger) Warn(msg string, fields ...Field) {
-	log.l.Warn(msg, fields...)
+	log.zapLogger().Warn(msg, fields...)
 }
 
 func (log *Logger) Error(msg string, fields ...Field) {
-	log.l.Error(msg, fields...)
+	log.zapLogger().Error(msg, fields...)
 }
 
 func (log *Logger) DPanic(msg string, fields ...Field) {
-	log.l.DPanic(msg, fields...)
+	log.zapLogger().DPanic(msg, fields...)
 }
 
 func (log *Logger) Panic(msg string, fields ...Field) {
-	log.l.Panic(msg, fields...)
+	log.zapLogger().Panic(msg, fields...)
 }
 
 func (log *Logger) Fatal(msg string, fields ...Field) {
-	log.l.Fatal(msg, fields...)
+	log.zapLogger().Fatal(msg, fields...)
 }
@@ -0,0 +1,91 @@
+// Package logtest 在测试中捕获gbase/log输出的日志，用于断言日志内容
+package logtest
+
+import (
+	"testing"
+
+	"github.com/kitdine/gbase/log"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// Entry 捕获的单条日志，包括With及调用时添加的字段
+type Entry = observer.LoggedEntry
+
+// Logs 捕获的日志，过滤方法返回新的Logs，可链式调用，如
+// logs.FilterLevelExact(zapcore.ErrorLevel).FilterLoggerName("http").FilterField(log.String("url", u))
+type Logs struct {
+	logs *observer.ObservedLogs
+}
+
+// Len 日志条数
+func (l *Logs) Len() int {
+	return l.logs.Len()
+}
+
+// All 按输出顺序返回全部日志
+func (l *Logs) All() []Entry {
+	return l.logs.All()
+}
+
+// TakeAll 返回并清空全部日志
+func (l *Logs) TakeAll() []Entry {
+	return l.logs.TakeAll()
+}
+
+// AllUntimed 返回全部日志，时间置为零值，便于直接比较
+func (l *Logs) AllUntimed() []Entry {
+	return l.logs.AllUntimed()
+}
+
+// FilterLevelExact 过滤指定级别的日志
+func (l *Logs) FilterLevelExact(level zapcore.Level) *Logs {
+	return &Logs{l.logs.FilterLevelExact(level)}
+}
+
+// FilterLoggerName 过滤指定logger输出的日志，全局logger的名称为空
+func (l *Logs) FilterLoggerName(name string) *Logs {
+	return l.Filter(func(e Entry) bool {
+		return e.LoggerName == name
+	})
+}
+
+// FilterMessage 过滤消息完全相同的日志
+func (l *Logs) FilterMessage(msg string) *Logs {
+	return &Logs{l.logs.FilterMessage(msg)}
+}
+
+// FilterMessageSnippet 过滤消息包含snippet的日志
+func (l *Logs) FilterMessageSnippet(snippet string) *Logs {
+	return &Logs{l.logs.FilterMessageSnippet(snippet)}
+}
+
+// FilterField 过滤包含指定字段(键和值均相同)的日志
+func (l *Logs) FilterField(field zapcore.Field) *Logs {
+	return &Logs{l.logs.FilterField(field)}
+}
+
+// FilterFieldKey 过滤包含指定字段名的日志
+func (l *Logs) FilterFieldKey(key string) *Logs {
+	return &Logs{l.logs.FilterFieldKey(key)}
+}
+
+// Filter 过滤keep返回true的日志
+func (l *Logs) Filter(keep func(Entry) bool) *Logs {
+	return &Logs{l.logs.Filter(keep)}
+}
+
+// Capture 捕获全局logger及全部子logger的日志，日志同时照常输出；测试结束时自动停止捕获。
+// 日志先经过各logger自身的级别过滤，捕获低级别日志时需同时调整logger级别
+func Capture(t testing.TB) *Logs {
+	t.Helper()
+	return CaptureLevel(t, zapcore.DebugLevel)
+}
+
+// CaptureLevel 与Capture相同，仅捕获不低于level的日志
+func CaptureLevel(t testing.TB, level zapcore.LevelEnabler) *Logs {
+	t.Helper()
+	core, logs := observer.New(level)
+	t.Cleanup(log.Observe(core))
+	return &Logs{logs}
+}
@@ -0,0 +1,50 @@
+package logtest
+
+import (
+	"testing"
+
+	"github.com/kitdine/gbase/log"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestCapture(t *testing.T) {
+	var captured *Logs
+	// 首次运行时全局logger尚未初始化；-count大于1时InitLogger已执行，因此显式设置级别，不依赖默认logger的debug级别
+	t.Run("global logger", func(t *testing.T) {
+		level, _ := log.GetLevel("")
+		if err := log.SetLevel("", "debug"); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { log.SetLevel("", level) })
+		captured = Capture(t)
+		log.Get("http").With(log.String("url", "/a")).Error("request failed", log.Int("status", 500))
+		log.Debug("debug entry")
+		if captured.Len() != 2 {
+			t.Fatalf("expected 2 entries, got %d", captured.Len())
+		}
+		entries := captured.FilterLoggerName("http").FilterField(log.String("url", "/a")).All()
+		if len(entries) != 1 || entries[0].Message != "request failed" || entries[0].ContextMap()["status"] != int64(500) {
+			t.Fatalf("unexpected entries: %+v", entries)
+		}
+	})
+	log.Info("after capture")
+	if captured.Len() != 2 {
+		t.Fatalf("capture should stop after the test, got %d entries", captured.Len())
+	}
+
+	log.InitLogger(log.GlobalConfig{Level: "info", Sinks: []log.SinkConfig{{Type: log.SinkDiscard}}},
+		log.ChildConfig{LoggerName: "db", Level: "warn", Sinks: []log.SinkConfig{{Type: log.SinkDiscard}}})
+	t.Run("after init", func(t *testing.T) {
+		logs := CaptureLevel(t, zapcore.WarnLevel)
+		log.Info("info entry")
+		log.Warn("warn entry")
+		log.Get("db").Info("filtered by logger level")
+		log.Get("db.pool").Error("pool exhausted")
+		if logs.Len() != 2 {
+			t.Fatalf("expected 2 entries, got %+v", logs.All())
+		}
+		if n := logs.FilterLevelExact(zapcore.ErrorLevel).FilterMessage("pool exhausted").FilterLoggerName("db.pool").Len(); n != 1 {
+			t.Fatalf("expected db.pool error entry, got %d", n)
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package log
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// observers 额外接收全部logger日志的core，如logtest中的内存core
+var observers []*zapcore.Core
+
+// Observe 将core添加到全局logger及全部子logger(包括之后创建的logger)，接收经各logger级别过滤及脱敏、未经采样及限流的日志，
+// 返回的函数用于移除core；主要用于测试中断言输出的日志，见logtest包
+func Observe(core zapcore.Core) (remove func()) {
+	observer := &core
+	reportRelease(withLogLock(func(retired *retiredSinks) error {
+		observers = append(observers, observer)
+		refreshObservers(retired)
+		return nil
+	}))
+	return func() {
+		reportRelease(withLogLock(func(retired *retiredSinks) error {
+			for i, o := range observers {
+				if o == observer {
+					observers = append(observers[:i:i], observers[i+1:]...)
+					refreshObservers(retired)
+					break
+				}
+			}
+			return nil
+		}))
+	}
+}
+
+// refreshObservers 按当前的observers重新构建core，初始化之前仅替换默认logger，子logger均委托给默认logger，调用方需持有logLock
+func refreshObservers(retired *retiredSinks) {
+	if initialize {
+		rebuildCores(retired)
+		return
+	}
+	globalState.core.Store(withObservers(defaultCore, globalState.level, newRedactor(RedactConfig{})))
+}
+
+// withObservers 将observers附加到core，observers接收的字段同样按logger的配置脱敏，observers自身的Check同样生效，调用方需持有logLock
+func withObservers(core zapcore.Core, level zapcore.LevelEnabler, redact *redactor) zapcore.Core {
+	if len(observers) == 0 {
+		return core
+	}
+	cores := []zapcore.Core{core}
+	for _, observer := range observers {
+		cores = append(cores, &levelFilterCore{Core: redact.wrapExternal(*observer), level: level})
+	}
+	return zapcore.NewTee(cores...)
+}
@@ -0,0 +1,487 @@
+package otel
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kitdine/gbase/log"
+	"go.uber.org/zap/zapcore"
+)
+
+// ScopeName 导出日志记录使用的instrumentation scope名称
+const ScopeName = "github.com/kitdine/gbase/log"
+
+// 导出配置的默认值
+const (
+	DefaultBatchSize     = 512
+	DefaultMaxQueueSize  = 2048
+	DefaultFlushInterval = time.Second
+	DefaultTimeout       = 10 * time.Second
+)
+
+// Config OTLP/HTTP日志导出配置，使用OTLP的JSON编码
+type Config struct {
+	// Endpoint 完整的日志接收地址，如http://localhost:4318/v1/logs
+	Endpoint string
+	// Headers 附加的请求头，如鉴权信息
+	Headers map[string]string
+	// ServiceName 写入resource的service.name
+	ServiceName string
+	// ResourceAttributes 其他resource属性
+	ResourceAttributes map[string]string
+	// BatchSize 单次请求的最大日志条数
+	BatchSize int
+	// FlushInterval 定时发送间隔
+	FlushInterval time.Duration
+	// MaxQueueSize 待发送队列上限，超出后丢弃新日志
+	MaxQueueSize int
+	// Timeout 单次请求超时时间
+	Timeout time.Duration
+	// Client 自定义http客户端，为空时使用默认客户端
+	Client *http.Client
+}
+
+// Core 将日志以OTLP日志记录的形式批量发送到collector的zapcore.Core
+type Core struct {
+	zapcore.LevelEnabler
+	exporter *exporter
+	fields   []zapcore.Field
+}
+
+// NewCore 创建OTLP导出core并启动后台发送协程，level为空时不限制级别；不再使用时需调用Close
+func NewCore(config Config, level zapcore.LevelEnabler) (*Core, error) {
+	if config.Endpoint == "" {
+		return nil, errors.New("log/otel: endpoint is required")
+	}
+	if level == nil {
+		level = zapcore.DebugLevel
+	}
+	return &Core{LevelEnabler: level, exporter: newExporter(config)}, nil
+}
+
+// RegisterSink 创建OTLP导出core并以name注册为log包的输出，之后可在SinkConfig.Type中引用该名称
+func RegisterSink(name string, config Config) (*Core, error) {
+	core, err := NewCore(config, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := log.RegisterCoreSink(name, core); err != nil {
+		core.Close()
+		return nil, err
+	}
+	return core, nil
+}
+
+func (c *Core) With(fields []zapcore.Field) zapcore.Core {
+	merged := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	merged = append(merged, c.fields...)
+	merged = append(merged, fields...)
+	return &Core{LevelEnabler: c.LevelEnabler, exporter: c.exporter, fields: merged}
+}
+
+func (c *Core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *Core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	all := fields
+	if len(c.fields) > 0 {
+		all = make([]zapcore.Field, 0, len(c.fields)+len(fields))
+		all = append(all, c.fields...)
+		all = append(all, fields...)
+	}
+	c.exporter.enqueue(newLogRecord(ent, all))
+	return nil
+}
+
+// Sync 立即发送队列中的日志
+func (c *Core) Sync() error {
+	return c.exporter.flush()
+}
+
+// Close 停止后台发送协程并发送剩余日志
+func (c *Core) Close() error {
+	return c.exporter.close()
+}
+
+// Dropped 返回因队列已满或发送失败而丢弃的日志条数
+func (c *Core) Dropped() uint64 {
+	return c.exporter.dropped.Load()
+}
+
+type exporter struct {
+	config   Config
+	client   *http.Client
+	resource resource
+
+	mu      sync.Mutex
+	queue   []logRecord
+	sendMu  sync.Mutex
+	dropped atomic.Uint64
+
+	wake      chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+func newExporter(config Config) *exporter {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	if config.MaxQueueSize <= 0 {
+		config.MaxQueueSize = DefaultMaxQueueSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultFlushInterval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	client := config.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	attrs := make(map[string]interface{}, len(config.ResourceAttributes)+1)
+	for k, v := range config.ResourceAttributes {
+		attrs[k] = v
+	}
+	if config.ServiceName != "" {
+		attrs["service.name"] = config.ServiceName
+	}
+
+	e := &exporter{
+		config:   config,
+		client:   client,
+		resource: resource{Attributes: keyValues(attrs)},
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+func (e *exporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C:
+			e.flush()
+		case <-e.wake:
+			e.flush()
+		}
+	}
+}
+
+func (e *exporter) enqueue(record logRecord) {
+	e.mu.Lock()
+	if len(e.queue) >= e.config.MaxQueueSize {
+		e.mu.Unlock()
+		e.dropped.Add(1)
+		return
+	}
+	e.queue = append(e.queue, record)
+	full := len(e.queue) >= e.config.BatchSize
+	e.mu.Unlock()
+	if full {
+		select {
+		case e.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// flush 发送队列中的全部日志，按BatchSize分批
+func (e *exporter) flush() error {
+	e.sendMu.Lock()
+	defer e.sendMu.Unlock()
+
+	e.mu.Lock()
+	records := e.queue
+	e.queue = nil
+	e.mu.Unlock()
+
+	var errs []error
+	for len(records) > 0 {
+		n := min(len(records), e.config.BatchSize)
+		if err := e.send(records[:n]); err != nil {
+			e.dropped.Add(uint64(n))
+			errs = append(errs, err)
+		}
+		records = records[n:]
+	}
+	return errors.Join(errs...)
+}
+
+func (e *exporter) send(records []logRecord) error {
+	body, err := json.Marshal(exportRequest{ResourceLogs: []resourceLogs{{
+		Resource:  e.resource,
+		ScopeLogs: []scopeLogs{{Scope: scope{Name: ScopeName}, LogRecords: records}},
+	}}})
+	if err != nil {
+		return fmt.Errorf("log/otel: encode logs: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.config.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("log/otel: create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.config.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("log/otel: export logs: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("log/otel: export logs: unexpected status %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (e *exporter) close() error {
+	e.closeOnce.Do(func() {
+		close(e.stop)
+		<-e.done
+		e.closeErr = e.flush()
+	})
+	return e.closeErr
+}
+
+// 以下为OTLP日志的JSON编码结构，参见opentelemetry-proto的logs/v1
+
+type exportRequest struct {
+	ResourceLogs []resourceLogs `json:"resourceLogs"`
+}
+
+type resourceLogs struct {
+	Resource  resource    `json:"resource"`
+	ScopeLogs []scopeLogs `json:"scopeLogs"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes,omitempty"`
+}
+
+type scopeLogs struct {
+	Scope      scope       `json:"scope"`
+	LogRecords []logRecord `json:"logRecords"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type logRecord struct {
+	TimeUnixNano         string     `json:"timeUnixNano"`
+	ObservedTimeUnixNano string     `json:"observedTimeUnixNano"`
+	SeverityNumber       int        `json:"severityNumber"`
+	SeverityText         string     `json:"severityText"`
+	Body                 anyValue   `json:"body"`
+	Attributes           []keyValue `json:"attributes,omitempty"`
+	Flags                uint32     `json:"flags,omitempty"`
+	TraceID              string     `json:"traceId,omitempty"`
+	SpanID               string     `json:"spanId,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string      `json:"stringValue,omitempty"`
+	BoolValue   *bool        `json:"boolValue,omitempty"`
+	IntValue    *string      `json:"intValue,omitempty"`
+	DoubleValue *float64     `json:"doubleValue,omitempty"`
+	ArrayValue  *arrayValue  `json:"arrayValue,omitempty"`
+	KvlistValue *kvlistValue `json:"kvlistValue,omitempty"`
+}
+
+type arrayValue struct {
+	Values []anyValue `json:"values"`
+}
+
+type kvlistValue struct {
+	Values []keyValue `json:"values"`
+}
+
+// severityNumber 按OpenTelemetry日志数据模型映射日志级别
+func severityNumber(level zapcore.Level) int {
+	switch level {
+	case zapcore.DebugLevel:
+		return 5
+	case zapcore.InfoLevel:
+		return 9
+	case zapcore.WarnLevel:
+		return 13
+	case zapcore.ErrorLevel:
+		return 17
+	case zapcore.DPanicLevel:
+		return 18
+	case zapcore.PanicLevel:
+		return 21
+	case zapcore.FatalLevel:
+		return 24
+	}
+	return 0
+}
+
+func newLogRecord(ent zapcore.Entry, fields []zapcore.Field) logRecord {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+
+	record := logRecord{
+		TimeUnixNano:         strconv.FormatInt(ent.Time.UnixNano(), 10),
+		ObservedTimeUnixNano: strconv.FormatInt(time.Now().UnixNano(), 10),
+		SeverityNumber:       severityNumber(ent.Level),
+		SeverityText:         ent.Level.CapitalString(),
+		Body:                 stringValue(ent.Message),
+	}
+	if traceID, ok := enc.Fields[TraceIDKey].(string); ok && isHexID(traceID, 16) {
+		record.TraceID = traceID
+		delete(enc.Fields, TraceIDKey)
+	}
+	if spanID, ok := enc.Fields[SpanIDKey].(string); ok && isHexID(spanID, 8) {
+		record.SpanID = spanID
+		delete(enc.Fields, SpanIDKey)
+	}
+	if flags, ok := enc.Fields[TraceFlagsKey].(string); ok {
+		if n, err := strconv.ParseUint(flags, 16, 8); err == nil {
+			record.Flags = uint32(n)
+			delete(enc.Fields, TraceFlagsKey)
+		}
+	}
+	if ent.LoggerName != "" {
+		enc.Fields["logger.name"] = ent.LoggerName
+	}
+	if ent.Caller.Defined {
+		enc.Fields["code.filepath"] = ent.Caller.File
+		enc.Fields["code.lineno"] = ent.Caller.Line
+		if ent.Caller.Function != "" {
+			enc.Fields["code.function"] = ent.Caller.Function
+		}
+	}
+	if ent.Stack != "" {
+		enc.Fields["exception.stacktrace"] = ent.Stack
+	}
+	record.Attributes = keyValues(enc.Fields)
+	return record
+}
+
+func isHexID(s string, size int) bool {
+	if len(s) != size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// keyValues 按key排序转换属性，保证输出稳定
+func keyValues(attrs map[string]interface{}) []keyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	kvs := make([]keyValue, 0, len(keys))
+	for _, k := range keys {
+		kvs = append(kvs, keyValue{Key: k, Value: toAnyValue(attrs[k])})
+	}
+	return kvs
+}
+
+func stringValue(s string) anyValue {
+	return anyValue{StringValue: &s}
+}
+
+func intValue(n int64) anyValue {
+	s := strconv.FormatInt(n, 10)
+	return anyValue{IntValue: &s}
+}
+
+// toAnyValue 将MapObjectEncoder编码后的值转换为OTLP的AnyValue
+func toAnyValue(v interface{}) anyValue {
+	switch v := v.(type) {
+	case string:
+		return stringValue(v)
+	case bool:
+		return anyValue{BoolValue: &v}
+	case int:
+		return intValue(int64(v))
+	case int8:
+		return intValue(int64(v))
+	case int16:
+		return intValue(int64(v))
+	case int32:
+		return intValue(int64(v))
+	case int64:
+		return intValue(v)
+	case uint:
+		return intValue(int64(v))
+	case uint8:
+		return intValue(int64(v))
+	case uint16:
+		return intValue(int64(v))
+	case uint32:
+		return intValue(int64(v))
+	case uint64:
+		return intValue(int64(v))
+	case uintptr:
+		return intValue(int64(v))
+	case float32:
+		f := float64(v)
+		return anyValue{DoubleValue: &f}
+	case float64:
+		return anyValue{DoubleValue: &v}
+	case time.Time:
+		return stringValue(v.Format(time.RFC3339Nano))
+	case time.Duration:
+		return stringValue(v.String())
+	case []byte:
+		return stringValue(string(v))
+	case []interface{}:
+		values := make([]anyValue, 0, len(v))
+		for _, item := range v {
+			values = append(values, toAnyValue(item))
+		}
+		return anyValue{ArrayValue: &arrayValue{Values: values}}
+	case map[string]interface{}:
+		return anyValue{KvlistValue: &kvlistValue{Values: keyValues(v)}}
+	case fmt.Stringer:
+		return stringValue(v.String())
+	}
+	return stringValue(fmt.Sprint(v))
+}
@@ -0,0 +1,37 @@
+// Package otel 为gbase log提供OpenTelemetry集成：从context提取trace_id、span_id，以及通过OTLP/HTTP导出日志
+package otel
+
+import (
+	"context"
+
+	"github.com/kitdine/gbase/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ExtractorName 注册到log包的context提取器名称
+const ExtractorName = "otel"
+
+// 提取的字段名，与log包内置的trace_id、span_id保持一致，OTLP导出时会还原为日志记录的traceId、spanId
+const (
+	TraceIDKey    = log.TraceIDKey
+	SpanIDKey     = log.SpanIDKey
+	TraceFlagsKey = "trace_flags"
+)
+
+// RegisterContextExtractor 将OpenTelemetry提取器注册到log包，此后*Ctx方法及slog的*Context方法自动附加当前span的trace信息
+func RegisterContextExtractor() {
+	log.RegisterContextExtractor(ExtractorName, ContextExtractor)
+}
+
+// ContextExtractor 从context中的有效span提取trace_id、span_id及trace_flags
+func ContextExtractor(ctx context.Context, fields []log.Field) []log.Field {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return fields
+	}
+	return append(fields,
+		log.String(TraceIDKey, spanContext.TraceID().String()),
+		log.String(SpanIDKey, spanContext.SpanID().String()),
+		log.String(TraceFlagsKey, spanContext.TraceFlags().String()),
+	)
+}
@@ -0,0 +1,162 @@
+package otel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kitdine/gbase/log"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+)
+
+// collector 模拟OTLP/HTTP collector，记录收到的请求
+type collector struct {
+	mu       sync.Mutex
+	requests []exportRequest
+	headers  []http.Header
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req exportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	c.requests = append(c.requests, req)
+	c.headers = append(c.headers, r.Header.Clone())
+	c.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+func (c *collector) records() []logRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var records []logRecord
+	for _, req := range c.requests {
+		for _, rl := range req.ResourceLogs {
+			for _, sl := range rl.ScopeLogs {
+				records = append(records, sl.LogRecords...)
+			}
+		}
+	}
+	return records
+}
+
+func attribute(record logRecord, key string) (anyValue, bool) {
+	for _, kv := range record.Attributes {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return anyValue{}, false
+}
+
+func TestOTLPSink(t *testing.T) {
+	c := &collector{}
+	server := httptest.NewServer(c)
+	defer server.Close()
+
+	core, err := RegisterSink("otlp-test", Config{
+		Endpoint:    server.URL + "/v1/logs",
+		Headers:     map[string]string{"Authorization": "Bearer token"},
+		ServiceName: "billing",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer core.Close()
+	defer log.UnregisterSink("otlp-test")
+	RegisterContextExtractor()
+	defer log.RemoveContextExtractor(ExtractorName)
+
+	err = log.InitLoggerE(log.GlobalConfig{Level: "info"}, log.ChildConfig{
+		LoggerName: "otel-orders",
+		Level:      "debug",
+		Sinks:      []log.SinkConfig{{Type: "otlp-test", Level: "info"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	orders := log.GetLoggerWithFileName("otel-orders")
+	orders.DebugCtx(ctx, "filtered by sink level")
+	orders.InfoCtx(ctx, "order created", log.Int("amount", 42), log.Any("items", []string{"a", "b"}))
+	orders.Warn("no span")
+	if err := core.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	records := c.records()
+	if len(records) != 2 {
+		t.Fatalf("want 2 records, got %d", len(records))
+	}
+	first := records[0]
+	if first.TraceID != traceID.String() || first.SpanID != spanID.String() || first.Flags != 1 {
+		t.Fatalf("trace correlation missing: %+v", first)
+	}
+	if *first.Body.StringValue != "order created" || first.SeverityNumber != 9 || first.SeverityText != "INFO" {
+		t.Fatalf("unexpected record %+v", first)
+	}
+	if amount, ok := attribute(first, "amount"); !ok || *amount.IntValue != "42" {
+		t.Fatalf("amount attribute missing: %+v", first.Attributes)
+	}
+	if items, ok := attribute(first, "items"); !ok || len(items.ArrayValue.Values) != 2 {
+		t.Fatalf("items attribute missing: %+v", first.Attributes)
+	}
+	if name, ok := attribute(first, "logger.name"); !ok || *name.StringValue != "otel-orders" {
+		t.Fatalf("logger name attribute missing: %+v", first.Attributes)
+	}
+	if _, ok := attribute(first, TraceIDKey); ok {
+		t.Fatal("trace id should be promoted to the record, not kept as attribute")
+	}
+	if records[1].TraceID != "" || records[1].SeverityText != "WARN" {
+		t.Fatalf("unexpected second record %+v", records[1])
+	}
+	if c.headers[0].Get("Authorization") != "Bearer token" {
+		t.Fatal("custom headers should be sent")
+	}
+	if rl := c.requests[0].ResourceLogs[0]; len(rl.Resource.Attributes) != 1 || *rl.Resource.Attributes[0].Value.StringValue != "billing" {
+		t.Fatalf("unexpected resource %+v", rl.Resource)
+	}
+}
+
+func TestExportFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	core, err := NewCore(Config{Endpoint: server.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer core.Close()
+	core.With([]log.Field{log.String("k", "v")}).Write(zapEntry(), nil)
+	if err := core.Sync(); err == nil {
+		t.Fatal("sync should report export failure")
+	}
+	if core.Dropped() != 1 {
+		t.Fatalf("dropped = %d, want 1", core.Dropped())
+	}
+	if _, err := NewCore(Config{}, nil); err == nil {
+		t.Fatal("missing endpoint should fail")
+	}
+}
+
+func zapEntry() zapcore.Entry {
+	return zapcore.Entry{Level: zapcore.ErrorLevel, Time: time.Now(), Message: "boom"}
+}
@@ -0,0 +1,58 @@
+package log
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOutputs(t *testing.T) {
+	resetLoggers(t)
+	dir := t.TempDir()
+	appFile, errorFile := filepath.Join(dir, "app.log"), filepath.Join(dir, "app.error.log")
+	config, err := LoadConfig(strings.NewReader(`
+global:
+  level: debug
+  outputs:
+    - file_name: `+appFile+`
+      max_level: info
+    - file_name: `+errorFile+`
+      min_level: warn
+      rotation: daily
+      file_pattern: app.error-%Y%m%d.log
+`), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := InitLoggerE(config.Global); err != nil {
+		t.Fatal(err)
+	}
+	Debug("debug entry")
+	Info("info entry")
+	Warn("warn entry")
+	Error("error entry")
+
+	app, _ := os.ReadFile(appFile)
+	if !strings.Contains(string(app), "debug entry") || !strings.Contains(string(app), "info entry") || strings.Contains(string(app), "warn entry") {
+		t.Fatalf("app.log should only keep debug and info, got %q", app)
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, "app.error-*.log"))
+	if len(matches) != 1 {
+		t.Fatalf("want one daily error file, got %v", matches)
+	}
+	errorLog, _ := os.ReadFile(matches[0])
+	if strings.Contains(string(errorLog), "info entry") || !strings.Contains(string(errorLog), "warn entry") || !strings.Contains(string(errorLog), "error entry") {
+		t.Fatalf("error log should only keep warn and above, got %q", errorLog)
+	}
+
+	err = AddChildLoggerE(ChildConfig{LoggerName: "bad", Outputs: []OutputConfig{{MinLevel: "error", MaxLevel: "info"}}})
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || len(configErr.Errors) != 2 {
+		t.Fatalf("want file_name and level range errors, got %v", err)
+	}
+	if configErr.Errors[1].Path != "children[0].outputs[0].max_level" {
+		t.Fatalf("unexpected error path %s", configErr.Errors[1].Path)
+	}
+}
@@ -0,0 +1,109 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// lockedBuffer 并发安全的输出，用于race检测
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) Sync() error {
+	return nil
+}
+
+func (b *lockedBuffer) lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.Split(strings.TrimSpace(b.buf.String()), "\n")
+}
+
+func TestConcurrentDerivation(t *testing.T) {
+	resetLoggers(t)
+	buf := &lockedBuffer{}
+	registerTestSink(t, "race-test-buffer", buf)
+	sinks := []SinkConfig{{Type: "race-test-buffer", Format: FormatJSON}}
+	InitLogger(GlobalConfig{Level: "info", Sinks: sinks}, ChildConfig{LoggerName: "shared", Level: "info", Sinks: sinks})
+	shared := GetLoggerWithFileName("shared")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				With(String("worker", fmt.Sprint(i))).Info("global with")
+				Named(fmt.Sprintf("worker%d", i)).Info("global named")
+				shared.With(Int("j", j)).Named("sub").Info("child derived")
+				GetLogger().Info("global plain")
+				Get(fmt.Sprintf("shared.worker%d", i%3)).Info("registry")
+			}
+		}(i)
+	}
+	// 派生的同时调整配置及级别
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 20; j++ {
+			SetLevel("shared", "debug")
+			SetLevelRules("shared.*=warn")
+			ReplaceChildLogger(ChildConfig{LoggerName: "shared", Level: "info", Sinks: sinks})
+		}
+	}()
+	wg.Wait()
+
+	for _, line := range buf.lines() {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("malformed line %q: %v", line, err)
+		}
+		switch entry["msg"] {
+		case "global plain":
+			if _, ok := entry["worker"]; ok || entry["logger"] != nil {
+				t.Fatalf("With/Named must not modify the global logger: %s", line)
+			}
+		case "child derived":
+			if entry["logger"] != "shared.sub" {
+				t.Fatalf("Named should not accumulate on the shared logger: %s", line)
+			}
+		}
+	}
+}
+
+func TestDerivationReturnsNewLogger(t *testing.T) {
+	resetLoggers(t)
+	buf := &lockedBuffer{}
+	registerTestSink(t, "derive-test-buffer", buf)
+	InitLogger(GlobalConfig{Level: "info", Sinks: []SinkConfig{{Type: "derive-test-buffer", Format: FormatJSON}}})
+	global := GetLogger()
+	if global.With(String("k", "v")) == global || global.Named("n") == global {
+		t.Fatal("With and Named should return a new Logger")
+	}
+	global.Named("a").Named("b").With(String("k", "v")).Info("derived")
+	global.Info("plain")
+	lines := buf.lines()
+	if !strings.Contains(lines[0], `"logger":"a.b"`) || !strings.Contains(lines[0], `"k":"v"`) {
+		t.Fatalf("derived logger got %s", lines[0])
+	}
+	if strings.Contains(lines[1], `"logger"`) || strings.Contains(lines[1], `"k"`) {
+		t.Fatalf("global logger should be unchanged, got %s", lines[1])
+	}
+	if !global.l.Core().Enabled(zapcore.InfoLevel) {
+		t.Fatal("global logger should still be enabled")
+	}
+}
@@ -0,0 +1,785 @@
+package log
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 脱敏方式
+const (
+	RedactMask = "mask"
+	RedactHash = "hash"
+	RedactDrop = "drop"
+)
+
+// 内置的字段值规则
+const (
+	RedactEmail      = "email"
+	RedactCreditCard = "credit_card"
+	RedactPhone      = "phone"
+)
+
+// redactMask mask方式的替换文本
+const redactMask = "***"
+
+var builtinRedactPatterns = map[string]*regexp.Regexp{
+	RedactEmail:      regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`),
+	RedactCreditCard: regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`),
+	RedactPhone:      regexp.MustCompile(`(?:\+\d{1,3}[ \-]?)?\b1[3-9]\d{9}\b|\+\d{8,15}\b`),
+}
+
+// builtinRedactChecks 内置规则匹配后的进一步校验，避免订单号、时间戳等数字被误判为卡号
+var builtinRedactChecks = map[string]func(string) bool{
+	RedactCreditCard: luhnValid,
+}
+
+// luhnValid 按Luhn算法校验卡号，忽略空格及连字符
+func luhnValid(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// RedactConfig 敏感信息脱敏配置，作用于该logger全部输出的字段；结构体中带有log:"redact"标签的字段始终按Mode脱敏
+type RedactConfig struct {
+	// Fields 需要脱敏的字段名，不区分大小写，*匹配任意字符，如password、authorization、*_token
+	Fields []string `yaml:"fields" json:"fields" mapstructure:"fields"`
+	// Patterns 字符串字段值的匹配规则，内置email、credit_card、phone，其余按正则表达式处理，仅脱敏匹配的部分
+	Patterns []string `yaml:"patterns" json:"patterns" mapstructure:"patterns"`
+	// Mode 脱敏方式，mask替换为***，hash替换为摘要，drop移除整个字段，默认mask
+	Mode string `yaml:"mode" json:"mode" mapstructure:"mode" validate:"omitempty,oneof=mask hash drop"`
+	// HashKey hash方式使用的HMAC-SHA256密钥；为空时使用不加盐的sha256，仅用于关联相同的值，
+	// 手机号、卡号等取值范围小的值可通过字典还原，不能保密
+	HashKey string `yaml:"hash_key" json:"hash_key" mapstructure:"hash_key"`
+}
+
+// compileRedactPattern 解析字段值规则
+func compileRedactPattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := builtinRedactPatterns[pattern]; ok {
+		return re, nil
+	}
+	return regexp.Compile(pattern)
+}
+
+// redactor 按配置脱敏字段
+type redactor struct {
+	names    *regexp.Regexp
+	patterns []redactPattern
+	mode     string
+	hashKey  []byte
+}
+
+// redactPattern 字段值规则，check不为nil时仅脱敏通过校验的匹配
+type redactPattern struct {
+	re    *regexp.Regexp
+	check func(string) bool
+}
+
+// newRedactor 根据配置创建redactor，忽略非法的正则表达式，配置校验时会报告
+func newRedactor(config RedactConfig) *redactor {
+	r := &redactor{mode: config.Mode}
+	if config.HashKey != "" {
+		r.hashKey = []byte(config.HashKey)
+	}
+	if len(config.Fields) > 0 {
+		names := make([]string, 0, len(config.Fields))
+		for _, name := range config.Fields {
+			names = append(names, strings.ReplaceAll(regexp.QuoteMeta(name), `\*`, ".*"))
+		}
+		r.names = regexp.MustCompile(`(?i)^(?:` + strings.Join(names, "|") + `)$`)
+	}
+	for _, pattern := range config.Patterns {
+		if re, err := compileRedactPattern(pattern); err == nil {
+			r.patterns = append(r.patterns, redactPattern{re: re, check: builtinRedactChecks[pattern]})
+		}
+	}
+	return r
+}
+
+// wrap 为本包通过zapcore.NewCore创建的core附加脱敏，其Check仅按级别过滤
+func (r *redactor) wrap(core zapcore.Core) zapcore.Core {
+	return &redactCore{Core: core, r: r}
+}
+
+// wrapExternal 为通过RegisterCoreSink注册的core及observers附加脱敏，保留core自身Check中的过滤及采样
+func (r *redactor) wrapExternal(core zapcore.Core) zapcore.Core {
+	return &externalRedactCore{Core: core, r: r}
+}
+
+func (r *redactor) matchName(name string) bool {
+	return r.names != nil && r.names.MatchString(name)
+}
+
+// mask 按脱敏方式替换value
+func (r *redactor) mask(value string) string {
+	if r.mode == RedactHash {
+		if r.hashKey != nil {
+			mac := hmac.New(sha256.New, r.hashKey)
+			mac.Write([]byte(value))
+			return "hmac:" + fmt.Sprintf("%x", mac.Sum(nil)[:8])
+		}
+		sum := sha256.Sum256([]byte(value))
+		return "sha256:" + fmt.Sprintf("%x", sum[:8])
+	}
+	return redactMask
+}
+
+// redactString 脱敏字符串中匹配值规则的部分，返回是否有匹配
+func (r *redactor) redactString(s string) (string, bool) {
+	matched := false
+	for _, p := range r.patterns {
+		s = p.re.ReplaceAllStringFunc(s, func(m string) string {
+			if p.check != nil && !p.check(m) {
+				return m
+			}
+			matched = true
+			return r.mask(m)
+		})
+	}
+	return s, matched
+}
+
+// active 是否配置了字段名或字段值规则
+func (r *redactor) active() bool {
+	return r.names != nil || len(r.patterns) > 0
+}
+
+// redactFields 返回脱敏后的字段，没有需要脱敏的字段时返回原切片
+func (r *redactor) redactFields(fields []Field) []Field {
+	var redacted []Field
+	for i, field := range fields {
+		f, keep, changed := r.redactField(field)
+		if !changed && redacted == nil {
+			continue
+		}
+		if redacted == nil {
+			redacted = make([]Field, 0, len(fields))
+			redacted = append(redacted, fields[:i]...)
+		}
+		if keep {
+			redacted = append(redacted, f)
+		}
+	}
+	if redacted == nil {
+		return fields
+	}
+	return redacted
+}
+
+// redactField 脱敏单个字段，keep为false时移除该字段；对象、数组及Any输出的值包装为在编码时逐个字段脱敏
+func (r *redactor) redactField(f Field) (redacted Field, keep, changed bool) {
+	if f.Type == zapcore.NamespaceType || f.Type == zapcore.SkipType {
+		return f, true, false
+	}
+	if r.matchName(f.Key) {
+		if r.mode == RedactDrop {
+			return f, false, true
+		}
+		return String(f.Key, r.mask(fieldString(f))), true, true
+	}
+	var s string
+	switch f.Type {
+	case zapcore.StringType:
+		s = f.String
+	case zapcore.ByteStringType:
+		s = string(f.Interface.([]byte))
+	case zapcore.StringerType:
+		if len(r.patterns) == 0 {
+			return f, true, false
+		}
+		s = fieldString(f)
+	case zapcore.ErrorType:
+		if len(r.patterns) == 0 {
+			return f, true, false
+		}
+		s = f.Interface.(error).Error()
+	case zapcore.ReflectType:
+		if f.Interface != nil && (r.active() || hasRedactTags(reflect.TypeOf(f.Interface))) {
+			return zap.Inline(reflectedField{key: f.Key, value: f.Interface, r: r}), true, true
+		}
+		return f, true, false
+	case zapcore.ObjectMarshalerType:
+		if r.active() {
+			return zap.Object(f.Key, redactedObject{m: f.Interface.(zapcore.ObjectMarshaler), r: r}), true, true
+		}
+		return f, true, false
+	case zapcore.InlineMarshalerType:
+		if r.active() {
+			return zap.Inline(redactedObject{m: f.Interface.(zapcore.ObjectMarshaler), r: r}), true, true
+		}
+		return f, true, false
+	case zapcore.ArrayMarshalerType:
+		if r.active() {
+			return zap.Array(f.Key, redactedArrayMarshaler{m: f.Interface.(zapcore.ArrayMarshaler), r: r}), true, true
+		}
+		return f, true, false
+	default:
+		return f, true, false
+	}
+	masked, matched := r.redactString(s)
+	if !matched {
+		return f, true, false
+	}
+	if r.mode == RedactDrop {
+		return f, false, true
+	}
+	return String(f.Key, masked), true, true
+}
+
+// fieldString 字段值的字符串形式，用于计算摘要
+func fieldString(f Field) string {
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	return fmt.Sprint(enc.Fields[f.Key])
+}
+
+// reflected 返回Any输出的值脱敏后的形式：带有log:"redact"标签的结构体按字段编码，
+// 配置了规则时map、结构体及切片按json编码规则转换为通用结构逐个字段脱敏，其余值原样返回
+func (r *redactor) reflected(value any) any {
+	if value == nil {
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	if hasRedactTags(v.Type()) {
+		return redactedValue(v, r)
+	}
+	if !r.active() {
+		return value
+	}
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return value
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map, reflect.Struct, reflect.Slice, reflect.Array:
+		if generic, ok := genericValue(value); ok {
+			return genericMarshaler(generic)
+		}
+	}
+	return value
+}
+
+// genericValue 按json编码规则将值转换为map[string]any、[]any等通用结构，遵循json标签及MarshalJSON
+func genericValue(value any) (any, bool) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return nil, false
+	}
+	return generic, true
+}
+
+// genericMarshaler 将通用结构中的对象及数组转换为ObjectMarshaler、ArrayMarshaler
+func genericMarshaler(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return genericObject(v)
+	case []any:
+		return genericArray(v)
+	}
+	return value
+}
+
+type genericObject map[string]any
+
+func (o genericObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	keys := make([]string, 0, len(o))
+	for key := range o {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		var err error
+		switch v := genericMarshaler(o[key]).(type) {
+		case string:
+			enc.AddString(key, v)
+		case bool:
+			enc.AddBool(key, v)
+		case zapcore.ObjectMarshaler:
+			err = enc.AddObject(key, v)
+		case zapcore.ArrayMarshaler:
+			err = enc.AddArray(key, v)
+		default:
+			err = enc.AddReflected(key, v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type genericArray []any
+
+func (a genericArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, value := range a {
+		var err error
+		switch v := genericMarshaler(value).(type) {
+		case string:
+			enc.AppendString(v)
+		case bool:
+			enc.AppendBool(v)
+		case zapcore.ObjectMarshaler:
+			err = enc.AppendObject(v)
+		case zapcore.ArrayMarshaler:
+			err = enc.AppendArray(v)
+		default:
+			err = enc.AppendReflected(v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redactCore 在编码前脱敏字段，包装每个输出的core，覆盖全部编码格式及输出
+type redactCore struct {
+	zapcore.Core
+	r *redactor
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.r.redactFields(fields)), r: c.r}
+}
+
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Core.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, c.r.redactFields(fields))
+}
+
+// externalRedactCore 先由内层core的Check决定是否写入，写入时脱敏字段
+type externalRedactCore struct {
+	zapcore.Core
+	r *redactor
+}
+
+func (c *externalRedactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &externalRedactCore{Core: c.Core.With(c.r.redactFields(fields)), r: c.r}
+}
+
+func (c *externalRedactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	checked := c.Core.Check(ent, nil)
+	if checked == nil {
+		return ce
+	}
+	return ce.AddCore(ent, &checkedRedactCore{Core: c.Core, r: c.r, checked: checked})
+}
+
+func (c *externalRedactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, c.r.redactFields(fields))
+}
+
+// checkedRedactCore 写入内层core的Check结果，调用位置等在Check之后设置的信息取自写入时的Entry
+type checkedRedactCore struct {
+	zapcore.Core
+	r       *redactor
+	checked *zapcore.CheckedEntry
+}
+
+func (c *checkedRedactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	c.checked.Entry = ent
+	c.checked.Write(c.r.redactFields(fields)...)
+	return nil
+}
+
+// reflectedField 在编码时脱敏Any输出的值
+type reflectedField struct {
+	key   string
+	value any
+	r     *redactor
+}
+
+func (f reflectedField) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return (&redactEncoder{ObjectEncoder: enc, r: f.r}).addReflected(f.key, f.value)
+}
+
+// redactedObject 编码时对对象中的字段按规则脱敏
+type redactedObject struct {
+	m zapcore.ObjectMarshaler
+	r *redactor
+}
+
+func (o redactedObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return o.m.MarshalLogObject(&redactEncoder{ObjectEncoder: enc, r: o.r})
+}
+
+// redactedArrayMarshaler 编码时对数组中的元素按规则脱敏
+type redactedArrayMarshaler struct {
+	m zapcore.ArrayMarshaler
+	r *redactor
+}
+
+func (a redactedArrayMarshaler) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	return a.m.MarshalLogArray(&redactArrayEncoder{ArrayEncoder: enc, r: a.r})
+}
+
+// redactEncoder 包装ObjectEncoder，对嵌套对象(包括map)的字段名及字符串值按规则脱敏
+type redactEncoder struct {
+	zapcore.ObjectEncoder
+	r *redactor
+}
+
+// redacted 字段名匹配规则时按脱敏方式写入并返回true
+func (e *redactEncoder) redacted(key string, value any) bool {
+	if !e.r.matchName(key) {
+		return false
+	}
+	if e.r.mode != RedactDrop {
+		e.ObjectEncoder.AddString(key, e.r.mask(fmt.Sprint(value)))
+	}
+	return true
+}
+
+func (e *redactEncoder) AddArray(key string, marshaler zapcore.ArrayMarshaler) error {
+	if e.redacted(key, marshaler) {
+		return nil
+	}
+	return e.ObjectEncoder.AddArray(key, redactedArrayMarshaler{m: marshaler, r: e.r})
+}
+
+func (e *redactEncoder) AddObject(key string, marshaler zapcore.ObjectMarshaler) error {
+	if e.redacted(key, marshaler) {
+		return nil
+	}
+	return e.ObjectEncoder.AddObject(key, redactedObject{m: marshaler, r: e.r})
+}
+
+func (e *redactEncoder) AddString(key, value string) {
+	if e.redacted(key, value) {
+		return
+	}
+	masked, matched := e.r.redactString(value)
+	if !matched || e.r.mode != RedactDrop {
+		e.ObjectEncoder.AddString(key, masked)
+	}
+}
+
+func (e *redactEncoder) AddByteString(key string, value []byte) {
+	e.AddString(key, string(value))
+}
+
+func (e *redactEncoder) AddReflected(key string, value any) error {
+	if e.redacted(key, value) {
+		return nil
+	}
+	return e.addReflected(key, value)
+}
+
+func (e *redactEncoder) addReflected(key string, value any) error {
+	switch v := e.r.reflected(value).(type) {
+	case string:
+		e.AddString(key, v)
+		return nil
+	case zapcore.ObjectMarshaler:
+		return e.ObjectEncoder.AddObject(key, redactedObject{m: v, r: e.r})
+	case zapcore.ArrayMarshaler:
+		return e.ObjectEncoder.AddArray(key, redactedArrayMarshaler{m: v, r: e.r})
+	default:
+		return e.ObjectEncoder.AddReflected(key, v)
+	}
+}
+
+func (e *redactEncoder) AddBinary(key string, value []byte) {
+	if !e.redacted(key, value) {
+		e.ObjectEncoder.AddBinary(key, value)
+	}
+}
+
+func (e *redactEncoder) AddBool(key string, value bool) {
+	if !e.redacted(key, value) {
+		e.ObjectEncoder.AddBool(key, value)
+	}
+}
+
+func (e *redactEncoder) AddComplex128(key string, value complex128) {
+	if !e.redacted(key, value) {
+		e.ObjectEncoder.AddComplex128(key, value)
+	}
+}
+
+func (e *redactEncoder) AddComplex64(key string, value complex64) {
+	if !e.redacted(key, value) {
+		e.ObjectEncoder.AddComplex64(key, value)
+	}
+}
+
+func (e *redactEncoder) AddDuration(key string, value time.Duration) {
+	if !e.redacted(key, value) {
+		e.ObjectEncoder.AddDuration(key, value)
+	}
+}
+
+func (e *redactEncoder) AddFloat64(key string, value float64) {
+	if !e.redacted(key, value) {
+		e.ObjectEncoder.AddFloat64(key, value)
+	}
+}
+
+func (e *redactEncoder) AddFloat32(key string, value float32) {
+	if !e.redacted(key, value) {
+		e.ObjectEncoder.AddFloat32(key, value)
+	}
+}
+
+func (e *redactEncoder) AddInt(key string, value int) {
+	if !e.redacted(key, value) {
+		e.ObjectEncoder.AddInt(key, value)
+	}
+}
+
+func (e *redactEncoder) AddInt64(key string, value int64) {
+	if !e.redacted(key, value) {
+		e.ObjectEncoder.AddInt64(key, value)
+	}
+}
+
+func (e *redactEncoder) AddInt32(key string, value int32) {
+	if !e.redacted(key, value) {
+		e.ObjectEncoder.AddInt32(key, value)
+	}
+}
+
+func (e *redactEncoder) AddInt16(key string, value int16) {
+	if !e.redacted(key, value) {
+		e.ObjectEncoder.AddInt16(key, value)
+	}
+}
+
+func (e *redactEncoder) AddInt8(key string, value int8) {
+	if !e.redacted(key, value) {
+		e.ObjectEncoder.AddInt8(key, value)
+	}
+}
+
+func (e *redactEncoder) AddTime(key string, value time.Time) {
+	if !e.redacted(key, value) {
+		e.ObjectEncoder.AddTime(key, value)
+	}
+}
+
+func (e *redactEncoder) AddUint(key string, value uint) {
+	if !e.redacted(key, value) {
+		e.ObjectEncoder.AddUint(key, value)
+	}
+}
+
+func (e *redactEncoder) AddUint64(key string, value uint64) {
+	if !e.redacted(key, value) {
+		e.ObjectEncoder.AddUint64(key, value)
+	}
+}
+
+func (e *redactEncoder) AddUint32(key string, value uint32) {
+	if !e.redacted(key, value) {
+		e.ObjectEncoder.AddUint32(key, value)
+	}
+}
+
+func (e *redactEncoder) AddUint16(key string, value uint16) {
+	if !e.redacted(key, value) {
+		e.ObjectEncoder.AddUint16(key, value)
+	}
+}
+
+func (e *redactEncoder) AddUint8(key string, value uint8) {
+	if !e.redacted(key, value) {
+		e.ObjectEncoder.AddUint8(key, value)
+	}
+}
+
+func (e *redactEncoder) AddUintptr(key string, value uintptr) {
+	if !e.redacted(key, value) {
+		e.ObjectEncoder.AddUintptr(key, value)
+	}
+}
+
+// redactArrayEncoder 包装ArrayEncoder，对数组中的字符串及嵌套对象按规则脱敏
+type redactArrayEncoder struct {
+	zapcore.ArrayEncoder
+	r *redactor
+}
+
+func (e *redactArrayEncoder) AppendString(value string) {
+	masked, matched := e.r.redactString(value)
+	if !matched || e.r.mode != RedactDrop {
+		e.ArrayEncoder.AppendString(masked)
+	}
+}
+
+func (e *redactArrayEncoder) AppendByteString(value []byte) {
+	e.AppendString(string(value))
+}
+
+func (e *redactArrayEncoder) AppendArray(marshaler zapcore.ArrayMarshaler) error {
+	return e.ArrayEncoder.AppendArray(redactedArrayMarshaler{m: marshaler, r: e.r})
+}
+
+func (e *redactArrayEncoder) AppendObject(marshaler zapcore.ObjectMarshaler) error {
+	return e.ArrayEncoder.AppendObject(redactedObject{m: marshaler, r: e.r})
+}
+
+func (e *redactArrayEncoder) AppendReflected(value any) error {
+	switch v := e.r.reflected(value).(type) {
+	case string:
+		e.AppendString(v)
+		return nil
+	case zapcore.ObjectMarshaler:
+		return e.AppendObject(v)
+	case zapcore.ArrayMarshaler:
+		return e.AppendArray(v)
+	default:
+		return e.ArrayEncoder.AppendReflected(v)
+	}
+}
+
+// redactTagTypes 缓存类型是否包含log:"redact"标签
+var redactTagTypes sync.Map
+
+// hasRedactTags 判断结构体(或其指针、切片、数组)及嵌套的结构体中是否有带log:"redact"标签的字段
+func hasRedactTags(t reflect.Type) bool {
+	if cached, ok := redactTagTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	has := findRedactTags(t, make(map[reflect.Type]bool))
+	redactTagTypes.Store(t, has)
+	return has
+}
+
+func findRedactTags(t reflect.Type, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return false
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.IsExported() && (field.Tag.Get("log") == "redact" || findRedactTags(field.Type, seen)) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactedValue 将带有脱敏标签的值转换为按字段编码的ObjectMarshaler或ArrayMarshaler，nil指针返回nil
+func redactedValue(v reflect.Value, r *redactor) any {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		return redactedArray{value: v, r: r}
+	}
+	return redactedStruct{value: v, r: r}
+}
+
+// redactedStruct 按字段编码结构体，字段名取json标签，带log:"redact"标签的字段按脱敏方式处理，
+// 其余字段经redactEncoder按字段名及字段值规则处理
+type redactedStruct struct {
+	value reflect.Value
+	r     *redactor
+}
+
+func (s redactedStruct) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	raw := enc
+	if e, ok := enc.(*redactEncoder); ok {
+		raw = e.ObjectEncoder
+	} else {
+		enc = &redactEncoder{ObjectEncoder: enc, r: s.r}
+	}
+	t := s.value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		value := s.value.Field(i)
+		if field.Anonymous && name == "" && value.Kind() == reflect.Struct {
+			if err := (redactedStruct{value: value, r: s.r}).MarshalLogObject(enc); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if field.Tag.Get("log") == "redact" {
+			if s.r.mode != RedactDrop {
+				raw.AddString(name, s.r.mask(fmt.Sprint(value.Interface())))
+			}
+			continue
+		}
+		if value.Kind() == reflect.String {
+			enc.AddString(name, value.String())
+			continue
+		}
+		if err := enc.AddReflected(name, value.Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redactedArray 逐个编码切片或数组中带有脱敏标签的元素
+type redactedArray struct {
+	value reflect.Value
+	r     *redactor
+}
+
+func (a redactedArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	if _, ok := enc.(*redactArrayEncoder); !ok {
+		enc = &redactArrayEncoder{ArrayEncoder: enc, r: a.r}
+	}
+	for i := 0; i < a.value.Len(); i++ {
+		if err := enc.AppendReflected(a.value.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type redactCard struct {
+	Number string `json:"number" log:"redact"`
+	Bank   string `json:"bank"`
+}
+
+type redactUser struct {
+	Name     string       `json:"name"`
+	Password string       `json:"password" log:"redact"`
+	Email    string       `json:"email"`
+	Cards    []redactCard `json:"cards"`
+	Internal string       `json:"-"`
+}
+
+func initRedactLogger(t *testing.T, sink string, redact RedactConfig) *lockedBuffer {
+	t.Helper()
+	resetLoggers(t)
+	buf := &lockedBuffer{}
+	registerTestSink(t, sink, buf)
+	err := InitLoggerE(GlobalConfig{
+		Level:      "info",
+		ConfigBase: ConfigBase{Redact: redact},
+		Sinks:      []SinkConfig{{Type: sink, Format: FormatJSON}, {Type: SinkDiscard, Format: FormatLogfmt}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func decodeLines(t *testing.T, buf *lockedBuffer) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	for _, line := range buf.lines() {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid json %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestRedactMask(t *testing.T) {
+	buf := initRedactLogger(t, "redact-test-mask", RedactConfig{
+		Fields:   []string{"password", "authorization", "*_token"},
+		Patterns: []string{RedactEmail, RedactCreditCard, RedactPhone},
+	})
+	Get("api").With(String("Authorization", "Bearer abc")).Info("request",
+		String("password", "secret"), Int("ACCESS_TOKEN", 42), String("user", "alice"),
+		String("note", "mail bob@example.com or call 13812345678"), Err(errors.New("card 4111 1111 1111 1111 declined")))
+	Info("user", Any("user", redactUser{Name: "bob", Password: "p@ss", Email: "bob@example.com",
+		Cards: []redactCard{{Number: "4111111111111111", Bank: "icbc"}}, Internal: "hidden"}))
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %v", entries)
+	}
+	request := entries[0]
+	for key, want := range map[string]any{
+		"Authorization": "***", "password": "***", "ACCESS_TOKEN": "***", "user": "alice",
+		"note": "mail *** or call ***", "error": "card *** declined",
+	} {
+		if request[key] != want {
+			t.Errorf("%s: want %v, got %v", key, want, request[key])
+		}
+	}
+	user, _ := json.Marshal(entries[1]["user"])
+	if want := `{"cards":[{"bank":"icbc","number":"***"}],"email":"***","name":"bob","password":"***"}`; string(user) != want {
+		t.Fatalf("struct tags should be honored:\nwant %s\ngot  %s", want, user)
+	}
+}
+
+func TestRedactHashAndDrop(t *testing.T) {
+	buf := initRedactLogger(t, "redact-test-hash", RedactConfig{Fields: []string{"token"}, Mode: RedactHash})
+	Info("hash", String("token", "abc"), Any("user", redactUser{Name: "bob", Password: "abc"}))
+	entries := decodeLines(t, buf)
+	token, _ := entries[0]["token"].(string)
+	password, _ := entries[0]["user"].(map[string]any)["password"].(string)
+	if !strings.HasPrefix(token, "sha256:") || len(token) != len("sha256:")+16 || token != password {
+		t.Fatalf("same values should hash to the same digest, got %q and %q", token, password)
+	}
+
+	buf = initRedactLogger(t, "redact-test-drop", RedactConfig{Fields: []string{"token"}, Patterns: []string{`secret-\w+`}, Mode: RedactDrop})
+	Info("drop", String("token", "abc"), String("note", "secret-42"),
+		Lazy(func() Field { return String("token", "lazy") }), Any("user", redactUser{Name: "bob", Password: "abc"}))
+	entry := decodeLines(t, buf)[0]
+	if _, ok := entry["token"]; ok {
+		t.Fatalf("dropped field should be removed: %v", entry)
+	}
+	if _, ok := entry["note"]; ok {
+		t.Fatalf("field matching a value pattern should be removed: %v", entry)
+	}
+	if user := entry["user"].(map[string]any); user["password"] != nil || user["name"] != "bob" {
+		t.Fatalf("tagged struct field should be removed: %v", entry)
+	}
+}
+
+func TestRedactConfigValidation(t *testing.T) {
+	resetLoggers(t)
+	err := InitLoggerE(GlobalConfig{Level: "info", ConfigBase: ConfigBase{Redact: RedactConfig{Patterns: []string{RedactEmail, "("}, Mode: "encrypt"}}})
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) {
+		t.Fatalf("want *ConfigError, got %v", err)
+	}
+	got := make(map[string]bool)
+	for _, fe := range configErr.Errors {
+		got[fe.Path] = true
+	}
+	if !got["global.redact.patterns[1]"] || !got["global.redact.mode"] || got["global.redact.patterns[0]"] {
+		t.Fatalf("unexpected errors: %v", err)
+	}
+}
+
+type redactHeaders struct {
+	Authorization string
+	Accept        string
+}
+
+func (h redactHeaders) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("authorization", h.Authorization)
+	enc.AddString("accept", h.Accept)
+	return nil
+}
+
+func TestRedactNested(t *testing.T) {
+	buf := initRedactLogger(t, "redact-test-nested", RedactConfig{
+		Fields:   []string{"password", "authorization", "*_token"},
+		Patterns: []string{RedactEmail},
+	})
+	observed, logs := observer.New(DebugLevel)
+	defer Observe(observed)()
+
+	type account struct {
+		Login    string            `json:"login"`
+		Password string            `json:"password"`
+		Extra    map[string]string `json:"extra"`
+	}
+	Info("nested",
+		Any("m", map[string]string{"password": "x", "user": "alice"}),
+		Any("headers", map[string][]string{"Authorization": {"Bearer abc"}, "Accept": {"bob@example.com"}}),
+		zap.Object("object", redactHeaders{Authorization: "Bearer abc", Accept: "json"}),
+		Any("account", account{Login: "bob", Password: "p", Extra: map[string]string{"refresh_token": "t"}}),
+		Any("accounts", []account{{Login: "amy", Password: "q"}}),
+	)
+
+	want := `{"accounts":[{"extra":null,"login":"amy","password":"***"}],` +
+		`"account":{"extra":{"refresh_token":"***"},"login":"bob","password":"***"},` +
+		`"headers":{"Accept":["***"],"Authorization":"***"},"m":{"password":"***","user":"alice"},` +
+		`"object":{"accept":"json","authorization":"***"}}`
+	for name, entry := range map[string]map[string]any{"sink": decodeLines(t, buf)[0], "observer": logs.All()[0].ContextMap()} {
+		got := make(map[string]any)
+		for _, key := range []string{"m", "headers", "object", "account", "accounts"} {
+			got[key] = entry[key]
+		}
+		data, _ := json.Marshal(got)
+		var normalized, expected any
+		json.Unmarshal(data, &normalized)
+		json.Unmarshal([]byte(want), &expected)
+		if !reflect.DeepEqual(normalized, expected) {
+			t.Errorf("%s: nested fields should be redacted:\nwant %s\ngot  %s", name, want, data)
+		}
+	}
+}
+
+func TestRedactCardLuhnAndHashKey(t *testing.T) {
+	buf := initRedactLogger(t, "redact-test-luhn", RedactConfig{
+		Fields:   []string{"token"},
+		Patterns: []string{RedactCreditCard},
+		Mode:     RedactHash,
+		HashKey:  "secret",
+	})
+	Info("luhn", String("order", "order 1760700000123456789"), String("card", "4111-1111-1111-1111"), String("token", "abc"))
+	entry := decodeLines(t, buf)[0]
+	if entry["order"] != "order 1760700000123456789" {
+		t.Fatalf("numbers failing the Luhn check should be kept, got %v", entry["order"])
+	}
+	card, _ := entry["card"].(string)
+	token, _ := entry["token"].(string)
+	if !strings.HasPrefix(card, "hmac:") || !strings.HasPrefix(token, "hmac:") || strings.Contains(token, "abc") {
+		t.Fatalf("hash mode with a key should use HMAC, got %q and %q", card, token)
+	}
+	if unkeyed := newRedactor(RedactConfig{Mode: RedactHash}).mask("abc"); unkeyed == token {
+		t.Fatal("HMAC digest should depend on the key")
+	}
+}
+
+// skipCore 在Check中丢弃以skip开头的消息
+type skipCore struct {
+	zapcore.Core
+}
+
+func (c skipCore) With(fields []zapcore.Field) zapcore.Core {
+	return skipCore{c.Core.With(fields)}
+}
+
+func (c skipCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if strings.HasPrefix(ent.Message, "skip") {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+func TestRedactCoreSinkCheck(t *testing.T) {
+	resetLoggers(t)
+	observed, logs := observer.New(DebugLevel)
+	if err := RegisterCoreSink("redact-test-core", skipCore{observed}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { UnregisterSink("redact-test-core") })
+	err := InitLoggerE(GlobalConfig{
+		Level:      "info",
+		ConfigBase: ConfigBase{ShowLineNumber: true, Redact: RedactConfig{Fields: []string{"password"}}},
+		Sinks:      []SinkConfig{{Type: "redact-test-core"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	Info("skip me", String("password", "p"))
+	With(String("password", "q")).Info("keep me", String("password", "p"))
+	entries := logs.AllUntimed()
+	if len(entries) != 1 || entries[0].Message != "keep me" {
+		t.Fatalf("the registered core's Check should filter entries, got %v", entries)
+	}
+	for _, field := range entries[0].Context {
+		if field.String != "***" {
+			t.Fatalf("fields should be redacted, got %v", entries[0].Context)
+		}
+	}
+	if !entries[0].Caller.Defined {
+		t.Fatal("caller set after Check should reach the registered core")
+	}
+}
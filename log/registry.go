@@ -0,0 +1,197 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	// levelRules 当前生效的级别规则
+	levelRules []levelRule
+	// autoLoggers 通过Get自动创建、没有对应配置的logger
+	autoLoggers = make(map[string]bool)
+)
+
+// levelRule 级别规则，pattern为logger名称(匹配自身及后代)、name.*(仅匹配后代)或*(匹配全部子logger)
+type levelRule struct {
+	pattern string
+	level   zapcore.Level
+}
+
+// parseLevelRule 解析"db.*=warn"形式的级别规则
+func parseLevelRule(rule string) (levelRule, error) {
+	pattern, level, ok := strings.Cut(rule, "=")
+	pattern, level = strings.TrimSpace(pattern), strings.TrimSpace(level)
+	if !ok || pattern == "" {
+		return levelRule{}, fmt.Errorf("log: invalid level rule %q, must be pattern=level", rule)
+	}
+	if pattern != "*" && (strings.Contains(strings.TrimSuffix(pattern, ".*"), "*") || strings.HasPrefix(pattern, ".")) {
+		return levelRule{}, fmt.Errorf("log: invalid level rule pattern %q", pattern)
+	}
+	zapLevel, err := ParseLevel(level)
+	if err != nil {
+		return levelRule{}, err
+	}
+	return levelRule{pattern: pattern, level: zapLevel}, nil
+}
+
+// parseLevelRules 解析全部级别规则，lenient为true时忽略非法规则
+func parseLevelRules(rules []string, lenient bool) ([]levelRule, error) {
+	parsed := make([]levelRule, 0, len(rules))
+	for _, rule := range rules {
+		r, err := parseLevelRule(rule)
+		if err != nil {
+			if lenient {
+				continue
+			}
+			return nil, err
+		}
+		parsed = append(parsed, r)
+	}
+	return parsed, nil
+}
+
+// matchLevelRule 查找name最具体的级别规则：自身名称优先，其次由近及远的祖先名称及祖先.*，最后为*
+func matchLevelRule(rules []levelRule, name string) (levelRule, bool) {
+	if len(rules) == 0 {
+		return levelRule{}, false
+	}
+	patterns := make(map[string]levelRule, len(rules))
+	for _, rule := range rules {
+		patterns[rule.pattern] = rule
+	}
+	if rule, ok := patterns[name]; ok {
+		return rule, true
+	}
+	for i := strings.LastIndexByte(name, '.'); i > 0; i = strings.LastIndexByte(name[:i], '.') {
+		ancestor := name[:i]
+		if rule, ok := patterns[ancestor]; ok {
+			return rule, true
+		}
+		if rule, ok := patterns[ancestor+".*"]; ok {
+			return rule, true
+		}
+	}
+	rule, ok := patterns["*"]
+	return rule, ok
+}
+
+// SetLevelRules 替换级别规则，如SetLevelRules("db.*=warn", "db.pool=debug")，规则立即应用到已存在的子logger，
+// 之后通过Get创建的logger同样按规则设置级别；匹配规则的logger以规则为准，不再匹配任何规则的logger恢复为配置的级别。
+// 规则不作用于全局logger，配置重新加载时被GlobalConfig.LevelRules替换
+func SetLevelRules(rules ...string) error {
+	parsed, err := parseLevelRules(rules, false)
+	if err != nil {
+		return err
+	}
+	logLock.Lock()
+	defer logLock.Unlock()
+	levelRules = parsed
+	refreshLevels(nil)
+	return nil
+}
+
+// LevelRules 返回当前生效的级别规则
+func LevelRules() []string {
+	logLock.RLock()
+	defer logLock.RUnlock()
+	rules := make([]string, 0, len(levelRules))
+	for _, rule := range levelRules {
+		rules = append(rules, rule.pattern+"="+rule.level.String())
+	}
+	return rules
+}
+
+// Get 按名称获取logger，不会返回nil：name为空时返回全局logger，已配置的子logger直接返回，
+// 否则自动创建，输出配置继承自最近的已配置祖先(如db.pool.conn依次查找db.pool、db)或全局配置，级别按级别规则设置
+func Get(name string) *Logger {
+	if name == "" {
+		return GetLogger()
+	}
+	logLock.RLock()
+	logger, ok := loggers[name]
+	logLock.RUnlock()
+	if ok {
+		return logger
+	}
+
+	logLock.Lock()
+	defer logLock.Unlock()
+	if logger, ok := loggers[name]; ok {
+		return logger
+	}
+	state := newLoggerState()
+	childStates[name] = state
+	loggers[name] = state.newLogger().Named(name)
+	autoLoggers[name] = true
+
+	if !initialize {
+		// 初始化之前使用默认logger，初始化时随其他logger一同构建core
+		state.core.Store(&swapCore{ref: &globalState.core})
+		return loggers[name]
+	}
+	config := autoConfig(name)
+	state.applyLevel(childLevel(name, config))
+	opened := &openedSinks{files: make(map[string]rotatingFile)}
+	state.core.Store(buildCore(config.coreConfig(), state, opened))
+	for fileName, hook := range opened.files {
+		files[fileName] = hook
+	}
+	asyncWriters = append(asyncWriters, opened.async...)
+	rateLimiters = append(rateLimiters, opened.limiters...)
+	return loggers[name]
+}
+
+// ListLoggers 返回全部子logger的名称，包括通过Get自动创建的logger，按名称排序
+func ListLoggers() []string {
+	logLock.RLock()
+	defer logLock.RUnlock()
+	names := make([]string, 0, len(loggers))
+	for name := range loggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// autoConfig 自动创建的logger的配置，等价于全部字段继承的子logger配置，调用方需持有logLock
+func autoConfig(name string) ChildConfig {
+	return resolveChildConfig(ChildConfig{LoggerName: name, Inherit: true}, globalConfig, childConfigs)
+}
+
+// effectiveChildConfigs 返回全部子logger生效的配置，包括自动创建的logger，调用方需持有logLock
+func effectiveChildConfigs() map[string]ChildConfig {
+	resolved := resolvedChildConfigs(globalConfig, childConfigs)
+	for name := range autoLoggers {
+		resolved[name] = autoConfig(name)
+	}
+	return resolved
+}
+
+// levelSource 按配置或级别规则确定的级别及匹配的规则
+type levelSource struct {
+	level zapcore.Level
+	rule  string
+}
+
+// childLevel 子logger的级别，匹配的级别规则优先于配置，调用方需持有logLock
+func childLevel(name string, config ChildConfig) levelSource {
+	if rule, ok := matchLevelRule(levelRules, name); ok {
+		return levelSource{level: rule.level, rule: rule.pattern}
+	}
+	return levelSource{level: getLogLevel(config.Level)}
+}
+
+// refreshLevels 重新设置配置或匹配的规则发生变化的子logger的级别：changed中的logger，以及生效的级别或匹配的规则
+// 与上次设置时不同的logger；其余logger保留运行时通过SetLevel调整的级别
+func refreshLevels(changed map[string]bool) {
+	for name, config := range effectiveChildConfigs() {
+		state, source := childStates[name], childLevel(name, config)
+		if changed[name] || state.levelSource == nil || *state.levelSource != source {
+			state.applyLevel(source)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestMatchLevelRule(t *testing.T) {
+	rules, err := parseLevelRules([]string{"*=error", "db.*=warn", "db.pool=debug"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]zapcore.Level{
+		"db":           zapcore.ErrorLevel,
+		"db.conn":      zapcore.WarnLevel,
+		"db.pool":      zapcore.DebugLevel,
+		"db.pool.conn": zapcore.DebugLevel,
+		"http":         zapcore.ErrorLevel,
+	} {
+		if got, ok := matchLevelRule(rules, name); !ok || got.level != want {
+			t.Errorf("%s: got %s, want %s", name, got.level, want)
+		}
+	}
+	for _, rule := range []string{"db", "=warn", "d*b=warn", "db.*=loud"} {
+		if _, err := parseLevelRule(rule); err == nil {
+			t.Errorf("rule %q should be rejected", rule)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	resetLoggers(t)
+	var buf bytes.Buffer
+	registerTestSink(t, "registry-test-buffer", zapcore.AddSync(&buf))
+	err := InitLoggerE(GlobalConfig{Level: "info", LevelRules: []string{"db.*=warn"}}, ChildConfig{
+		LoggerName: "db",
+		Level:      "info",
+		Sinks:      []SinkConfig{{Type: "registry-test-buffer", Format: FormatJSON}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn := Get("db.pool.conn")
+	if conn == nil || Get("db.pool.conn") != conn {
+		t.Fatal("Get should create the logger once and reuse it")
+	}
+	conn.Info("hidden")
+	conn.Warn("visible")
+	if strings.Contains(buf.String(), "hidden") || !strings.Contains(buf.String(), `"logger":"db.pool.conn"`) {
+		t.Fatalf("auto-created logger should use db's sinks and the db.* rule, got %q", buf.String())
+	}
+	if names := ListLoggers(); len(names) != 2 || names[0] != "db" || names[1] != "db.pool.conn" {
+		t.Fatalf("unexpected loggers %v", names)
+	}
+
+	// 规则更新后已创建的logger立即生效，不再匹配规则的logger恢复为配置的级别
+	if err := SetLevelRules("db.pool=debug", "db=error"); err != nil {
+		t.Fatal(err)
+	}
+	if !conn.l.Core().Enabled(zapcore.DebugLevel) {
+		t.Fatal("db.pool rule should apply to db.pool.conn")
+	}
+	if level, _ := GetLevel("db"); level != "error" {
+		t.Fatalf("db level = %s, want error", level)
+	}
+	if err := SetLevelRules(); err != nil {
+		t.Fatal(err)
+	}
+	if level, _ := GetLevel("db"); level != "info" {
+		t.Fatalf("db should return to its configured level, got %s", level)
+	}
+
+	// 自动创建的logger再被配置时，已获取的Logger继续有效
+	if err := AddChildLoggerE(ChildConfig{LoggerName: "db.pool.conn", Level: "error"}); err != nil {
+		t.Fatal(err)
+	}
+	if GetLoggerWithFileName("db.pool.conn") != conn || conn.l.Core().Enabled(zapcore.WarnLevel) {
+		t.Fatal("configuring an auto-created logger should update it in place")
+	}
+}
+
+func TestRefreshLevelsKeepsRuntimeLevels(t *testing.T) {
+	resetLoggers(t)
+	if err := InitLoggerE(GlobalConfig{Level: "info"}, ChildConfig{LoggerName: "db", Level: "info", Inherit: true}); err != nil {
+		t.Fatal(err)
+	}
+	Get("db.pool")
+	Get("cache")
+	NewSlog("payments")
+	for _, name := range []string{"db", "db.pool", "cache", "payments"} {
+		if err := SetLevel(name, "debug"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := AddChildLoggerE(ChildConfig{LoggerName: "http", Level: "warn"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReplaceChildLogger(ChildConfig{LoggerName: "db", Level: "error", Inherit: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetLevelRules("cache=warn"); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"db": "error", "db.pool": "error", "cache": "warn", "payments": "debug", "http": "warn"} {
+		if level, _ := GetLevel(name); level != want {
+			t.Errorf("%s level = %s, want %s", name, level, want)
+		}
+	}
+}
+
+func TestInitLoggerKeepsGlobalRuntimeLevel(t *testing.T) {
+	resetLoggers(t)
+	InitLogger(GlobalConfig{Level: "info"}, ChildConfig{LoggerName: "a", Level: "info"})
+	for _, name := range []string{"", "a"} {
+		if err := SetLevel(name, "debug"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	InitLogger(GlobalConfig{Level: "info"}, ChildConfig{LoggerName: "a", Level: "info"}, ChildConfig{LoggerName: "b", Level: "info"})
+	for _, name := range []string{"", "a"} {
+		if level, _ := GetLevel(name); level != "debug" {
+			t.Errorf("%q level = %s, want debug", name, level)
+		}
+	}
+	InitLogger(GlobalConfig{Level: "warn"})
+	if level, _ := GetLevel(""); level != "warn" {
+		t.Fatalf("a changed global level should be applied, got %s", level)
+	}
+}
@@ -0,0 +1,72 @@
+package log
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// Rotate 立即切割全部日志文件，切割前先写出异步缓冲区中的日志
+func Rotate() error {
+	logLock.RLock()
+	cores, hooks := allCores(), make([]rotatingFile, 0, len(files))
+	for _, hook := range files {
+		hooks = append(hooks, hook)
+	}
+	logLock.RUnlock()
+	err := syncAll(cores)
+	for _, hook := range hooks {
+		err = errors.Join(err, hook.Rotate())
+	}
+	return err
+}
+
+// Reopen 关闭全部日志文件，下次写入时按原路径重新打开，
+// 用于logrotate等外部工具移动或copytruncate截断文件之后；重新打开前先写出异步缓冲区中的日志
+func Reopen() error {
+	logLock.RLock()
+	cores, hooks := allCores(), make([]rotatingFile, 0, len(files))
+	for _, hook := range files {
+		hooks = append(hooks, hook)
+	}
+	logLock.RUnlock()
+	err := syncAll(cores)
+	for _, hook := range hooks {
+		err = errors.Join(err, hook.Reopen())
+	}
+	return err
+}
+
+// ReopenOnSignal 收到信号时调用Reopen重新打开日志文件，未指定信号时监听SIGHUP及SIGUSR1(Windows仅SIGHUP)，
+// 返回的函数用于停止监听
+func ReopenOnSignal(signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = defaultReopenSignals
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	quit := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-quit:
+				return
+			case sig := <-ch:
+				if err := Reopen(); err != nil {
+					Error("reopen log files failed", String("signal", sig.String()), Err(err))
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(quit)
+			<-done
+		})
+	}
+}
@@ -0,0 +1,96 @@
+//go:build !windows
+
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func initFileLogger(t *testing.T, fileName string) *Logger {
+	t.Helper()
+	resetLoggers(t)
+	err := InitLoggerE(GlobalConfig{Level: "info"}, ChildConfig{
+		LoggerName: "reopen",
+		Level:      "info",
+		Sinks:      []SinkConfig{{Type: SinkFile, FileLogConfig: FileLogConfig{FileName: fileName}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return GetLoggerWithFileName("reopen")
+}
+
+func TestReopen(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "app.log")
+	logger := initFileLogger(t, fileName)
+
+	logger.Info("before move")
+	if err := os.Rename(fileName, fileName+".1"); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("still old file")
+	if err := Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("after reopen")
+
+	moved, _ := os.ReadFile(fileName + ".1")
+	current, _ := os.ReadFile(fileName)
+	if !strings.Contains(string(moved), "still old file") || strings.Contains(string(moved), "after reopen") {
+		t.Fatalf("moved file got %q", moved)
+	}
+	if !strings.Contains(string(current), "after reopen") {
+		t.Fatalf("reopened file got %q", current)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "app.log")
+	logger := initFileLogger(t, fileName)
+
+	logger.Info("before rotate")
+	if err := Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("after rotate")
+	if names := listDir(t, dir); len(names) != 2 {
+		t.Fatalf("want current and rotated file, got %v", names)
+	}
+	if current, _ := os.ReadFile(fileName); strings.Contains(string(current), "before rotate") {
+		t.Fatalf("current file should only contain new entries, got %q", current)
+	}
+}
+
+func TestReopenOnSignal(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "app.log")
+	logger := initFileLogger(t, fileName)
+	stop := ReopenOnSignal(syscall.SIGUSR1)
+	defer stop()
+
+	logger.Info("before signal")
+	if err := os.Rename(fileName, fileName+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		logger.Info("after signal")
+		if _, err := os.Stat(fileName); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("log file should be reopened after SIGUSR1")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,459 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// 日志文件切割策略
+const (
+	// RotationSize 按文件大小切割，由lumberjack实现，默认策略
+	RotationSize = "size"
+	// RotationDaily 每天一个文件
+	RotationDaily = "daily"
+	// RotationHourly 每小时一个文件
+	RotationHourly = "hourly"
+	// RotationSizeTime 每天一个文件，单个文件超过MaxSize时在当天内继续切割
+	RotationSizeTime = "size+time"
+)
+
+const (
+	megabyte       = 1024 * 1024
+	defaultMaxSize = 100
+)
+
+// rotatingFile 日志文件输出，按大小切割时为sizeRotator，按时间切割时为timeRotator
+type rotatingFile interface {
+	io.WriteCloser
+	Rotate() error
+	Reopen() error
+}
+
+// rotation 返回切割策略，为空时按大小切割
+func (c FileLogConfig) rotation() string {
+	if c.Rotation == "" {
+		return RotationSize
+	}
+	return c.Rotation
+}
+
+// newRotatingFile 按配置创建日志文件输出
+func newRotatingFile(config FileLogConfig) rotatingFile {
+	if config.rotation() == RotationSize {
+		return newSizeRotator(config)
+	}
+	return newTimeRotator(config)
+}
+
+// reusable 判断已打开的日志文件是否可以继续用于config
+func reusable(hook rotatingFile, config FileLogConfig) bool {
+	switch h := hook.(type) {
+	case *sizeRotator:
+		return h.config == config
+	case *timeRotator:
+		return h.config == config
+	}
+	return false
+}
+
+// sizeRotator 按大小切割的日志文件，由lumberjack写入及命名，切割时机由sizeRotator控制以便执行切割后处理
+type sizeRotator struct {
+	*lumberjack.Logger
+	config  FileLogConfig
+	maxSize int64
+
+	mu     sync.Mutex
+	size   int64
+	opened bool
+
+	post *postRotate
+}
+
+// backupTimeFormat lumberjack切割出的文件名中的时间格式
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+func newSizeRotator(config FileLogConfig) *sizeRotator {
+	maxSize := config.MaxSize
+	if maxSize == 0 {
+		maxSize = defaultMaxSize
+	}
+	ext := filepath.Ext(config.FileName)
+	prefix := strings.TrimSuffix(filepath.Base(config.FileName), ext) + "-"
+	match := regexp.MustCompile("^" + regexp.QuoteMeta(prefix) + `\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}\.\d{3}` +
+		regexp.QuoteMeta(ext) + `(\.gz|\.zst)?$`)
+	return &sizeRotator{
+		// lumberjack不自行切割、压缩及清理，统一由postRotate处理
+		Logger:  &lumberjack.Logger{Filename: config.FileName, MaxSize: math.MaxInt32},
+		config:  config,
+		maxSize: int64(maxSize) * megabyte,
+		post:    newPostRotate(config, filepath.Dir(config.FileName), match),
+	}
+}
+
+func (r *sizeRotator) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.opened {
+		r.size = 0
+		if info, err := os.Stat(r.Filename); err == nil {
+			r.size = info.Size()
+		}
+		r.opened = true
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.Logger.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Rotate 立即切割当前文件
+func (r *sizeRotator) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rotate()
+}
+
+// Close 关闭当前文件并等待切割后处理完成
+func (r *sizeRotator) Close() error {
+	r.mu.Lock()
+	err := r.Logger.Close()
+	r.opened = false
+	r.mu.Unlock()
+	r.post.wait()
+	return err
+}
+
+// Reopen 关闭当前文件，下次写入时按原路径重新打开，用于配合外部工具移动或截断文件
+func (r *sizeRotator) Reopen() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.opened = false
+	return r.Logger.Close()
+}
+
+func (r *sizeRotator) rotate() error {
+	// lumberjack按UTC时间命名切割出的文件，精确到毫秒
+	since := time.Now().UTC().Truncate(time.Millisecond)
+	if err := r.Logger.Rotate(); err != nil {
+		return err
+	}
+	r.size, r.opened = 0, true
+	if backup := r.latestBackup(since); backup != "" {
+		r.post.process(backup, r.Filename)
+	}
+	return nil
+}
+
+// latestBackup 查找since之后lumberjack切割出的文件
+func (r *sizeRotator) latestBackup(since time.Time) string {
+	ext := filepath.Ext(r.Filename)
+	prefix := strings.TrimSuffix(filepath.Base(r.Filename), ext) + "-"
+	entries, err := os.ReadDir(filepath.Dir(r.Filename))
+	if err != nil {
+		return ""
+	}
+	latest := ""
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		t, err := time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext))
+		if err != nil || t.Before(since) {
+			continue
+		}
+		if name > latest {
+			latest = name
+		}
+	}
+	if latest == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(r.Filename), latest)
+}
+
+// timeRotator 按时间切割的日志文件，文件名由FilePattern按时间段展开，
+// 例如app.log按天切割时写入app-2026-10-17.log；Close后再次写入会重新打开文件
+type timeRotator struct {
+	config  FileLogConfig
+	pattern string
+	hourly  bool
+	maxSize int64
+	// now 当前时间，测试时替换
+	now func() time.Time
+
+	mu       sync.Mutex
+	file     *os.File
+	name     string
+	size     int64
+	boundary time.Time
+	index    int
+
+	post *postRotate
+}
+
+func newTimeRotator(config FileLogConfig) *timeRotator {
+	r := &timeRotator{
+		config:  config,
+		pattern: filePattern(config),
+		hourly:  config.Rotation == RotationHourly,
+		now:     time.Now,
+	}
+	r.post = newPostRotate(config, filepath.Dir(r.pattern), patternRegexp(filepath.Base(r.pattern)))
+	if config.Rotation == RotationSizeTime {
+		r.maxSize = int64(config.MaxSize) * megabyte
+		if r.maxSize == 0 {
+			r.maxSize = defaultMaxSize * megabyte
+		}
+	}
+	return r
+}
+
+// filePattern 返回完整的文件名模板，未配置时由FileName加上日期生成，相对路径相对于FileName所在目录
+func filePattern(config FileLogConfig) string {
+	pattern := config.FilePattern
+	if pattern == "" {
+		ext := filepath.Ext(config.FileName)
+		layout := "-%Y-%m-%d"
+		if config.Rotation == RotationHourly {
+			layout += "-%H"
+		}
+		return strings.TrimSuffix(config.FileName, ext) + layout + ext
+	}
+	if filepath.IsAbs(pattern) {
+		return pattern
+	}
+	return filepath.Join(filepath.Dir(config.FileName), pattern)
+}
+
+// expandPattern 展开文件名模板，支持%Y、%m、%d、%H、%i(当前时间段内的序号)及%%；
+// 模板不含%i且序号大于0时，序号插入到扩展名之前，如app-2026-10-17.1.log
+func expandPattern(pattern string, t time.Time, index int) string {
+	var b strings.Builder
+	hasIndex := false
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' || i == len(pattern)-1 {
+			b.WriteByte(pattern[i])
+			continue
+		}
+		i++
+		switch pattern[i] {
+		case 'Y':
+			b.WriteString(strconv.Itoa(t.Year()))
+		case 'm':
+			fmt.Fprintf(&b, "%02d", int(t.Month()))
+		case 'd':
+			fmt.Fprintf(&b, "%02d", t.Day())
+		case 'H':
+			fmt.Fprintf(&b, "%02d", t.Hour())
+		case 'i':
+			hasIndex = true
+			b.WriteString(strconv.Itoa(index))
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(pattern[i])
+		}
+	}
+	name := b.String()
+	if index > 0 && !hasIndex {
+		ext := filepath.Ext(name)
+		name = strings.TrimSuffix(name, ext) + "." + strconv.Itoa(index) + ext
+	}
+	return name
+}
+
+// patternRegexp 匹配模板生成的全部文件，包括带序号及压缩后的文件
+func patternRegexp(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteByte('^')
+	hasIndex := false
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' || i == len(pattern)-1 {
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+			continue
+		}
+		i++
+		switch pattern[i] {
+		case 'Y':
+			b.WriteString(`\d{4}`)
+		case 'm', 'd', 'H':
+			b.WriteString(`\d{2}`)
+		case 'i':
+			hasIndex = true
+			b.WriteString(`\d+`)
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i-1 : i+1]))
+		}
+	}
+	expr := b.String()
+	if !hasIndex {
+		ext := regexp.QuoteMeta(filepath.Ext(pattern))
+		expr = strings.TrimSuffix(expr, ext) + `(\.\d+)?` + ext
+	}
+	return regexp.MustCompile(expr + `(\.gz|\.zst)?$`)
+}
+
+// periodStart 返回t所在时间段的起点，按UTC配置决定使用UTC还是本地时间
+func (r *timeRotator) periodStart(t time.Time) time.Time {
+	if r.config.UTC {
+		t = t.UTC()
+	} else {
+		t = t.Local()
+	}
+	hour := 0
+	if r.hourly {
+		hour = t.Hour()
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), hour, 0, 0, 0, t.Location())
+}
+
+func (r *timeRotator) nextBoundary(start time.Time) time.Time {
+	if r.hourly {
+		return start.Add(time.Hour)
+	}
+	return time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, start.Location())
+}
+
+func (r *timeRotator) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	switch {
+	case r.file == nil || !now.Before(r.boundary):
+		if err := r.openPeriod(now); err != nil {
+			return 0, err
+		}
+	case r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize:
+		if err := r.openIndex(r.index + 1); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *timeRotator) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	return r.file.Sync()
+}
+
+// Close 关闭当前文件并等待切割后处理完成，当前文件不做切割后处理
+func (r *timeRotator) Close() error {
+	r.mu.Lock()
+	err := r.closeFile()
+	r.mu.Unlock()
+	r.post.wait()
+	return err
+}
+
+// Rotate 立即切换到当前时间段的下一个序号的文件
+func (r *timeRotator) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return r.openPeriod(r.now())
+	}
+	return r.openIndex(r.index + 1)
+}
+
+// Reopen 关闭当前文件，下次写入时重新打开当前时间段的文件
+func (r *timeRotator) Reopen() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closeFile()
+}
+
+// openPeriod 打开now所在时间段的文件，时间段内已有文件时继续写入序号最大的文件
+func (r *timeRotator) openPeriod(now time.Time) error {
+	start := r.periodStart(now)
+	r.boundary = r.nextBoundary(start)
+	index := 0
+	for r.post.exists(expandPattern(r.pattern, start, index+1)) {
+		index++
+	}
+	// 已经过切割后处理的文件不再追加写入
+	if name := expandPattern(r.pattern, start, index); r.post.exists(name) && !fileExists(name) {
+		index++
+	}
+	reopened := r.file == nil
+	if err := r.openAt(start, index); err != nil {
+		return err
+	}
+	// 进程重启或Reopen之前切割出的文件可能尚未处理
+	if reopened {
+		for _, name := range r.post.unprocessed(r.name) {
+			r.post.process(name, r.name)
+		}
+	}
+	return nil
+}
+
+func (r *timeRotator) openIndex(index int) error {
+	return r.openAt(r.periodStart(r.boundary.Add(-time.Nanosecond)), index)
+}
+
+func (r *timeRotator) openAt(start time.Time, index int) error {
+	name := expandPattern(r.pattern, start, index)
+	if r.file != nil && name == r.name {
+		return nil
+	}
+	rotated := r.name
+	if err := r.closeFile(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return fmt.Errorf("log: can't make directories for new logfile: %w", err)
+	}
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("log: can't open new logfile: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.file, r.name, r.size, r.index = file, name, info.Size(), index
+	if rotated != "" {
+		r.post.process(rotated, name)
+	}
+	return nil
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+// closeFile 关闭当前文件
+func (r *timeRotator) closeFile() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file, r.name = nil, ""
+	return err
+}
@@ -0,0 +1,191 @@
+package log
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestExpandPattern(t *testing.T) {
+	at := time.Date(2026, 10, 7, 9, 30, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		pattern string
+		index   int
+		want    string
+	}{
+		{"app-%Y-%m-%d.log", 0, "app-2026-10-07.log"},
+		{"app-%Y-%m-%d.log", 2, "app-2026-10-07.2.log"},
+		{"app-%Y%m%d%H-%i.log", 3, "app-2026100709-3.log"},
+		{"100%%-%d.log", 0, "100%-07.log"},
+	} {
+		if got := expandPattern(tt.pattern, at, tt.index); got != tt.want {
+			t.Errorf("expandPattern(%q, %d) = %q, want %q", tt.pattern, tt.index, got, tt.want)
+		}
+		if !patternRegexp(tt.pattern).MatchString(tt.want + ".gz") {
+			t.Errorf("patternRegexp(%q) should match %q", tt.pattern, tt.want+".gz")
+		}
+	}
+}
+
+func TestDailyRotation(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{now: time.Date(2026, 10, 17, 23, 59, 0, 0, time.UTC)}
+	r := newTimeRotator(FileLogConfig{FileName: filepath.Join(dir, "app.log"), Rotation: RotationDaily, UTC: true, MaxBackups: 2})
+	r.now = clock.Now
+	defer r.Close()
+
+	for day := 0; day < 4; day++ {
+		if _, err := r.Write([]byte("entry\n")); err != nil {
+			t.Fatal(err)
+		}
+		clock.now = clock.now.Add(24 * time.Hour)
+	}
+	r.Close()
+	// MaxBackups不包括正在写入的文件
+	want := []string{"app-2026-10-18.log", "app-2026-10-19.log", "app-2026-10-20.log"}
+	got := listDir(t, dir)
+	if !slices.Equal(got, want) {
+		t.Fatalf("want %v after retention, got %v", want, got)
+	}
+}
+
+func TestHourlyRotationLocalTime(t *testing.T) {
+	dir := t.TempDir()
+	loc := time.FixedZone("UTC+8", 8*3600)
+	r := newTimeRotator(FileLogConfig{FileName: filepath.Join(dir, "app.log"), Rotation: RotationHourly})
+	r.now = func() time.Time { return time.Date(2026, 10, 17, 1, 15, 0, 0, loc) }
+	defer r.Close()
+
+	if _, err := r.Write([]byte("entry\n")); err != nil {
+		t.Fatal(err)
+	}
+	local := r.now().Local()
+	want := expandPattern("app-%Y-%m-%d-%H.log", local, 0)
+	if got := listDir(t, dir); len(got) != 1 || got[0] != want {
+		t.Fatalf("want %s, got %v", want, got)
+	}
+}
+
+func TestSizeTimeRotation(t *testing.T) {
+	dir := t.TempDir()
+	config := FileLogConfig{FileName: filepath.Join(dir, "app.log"), Rotation: RotationSizeTime, FilePattern: "app-%Y%m%d-%i.log", UTC: true, MaxSize: 1, Compress: true}
+	now := func() time.Time { return time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC) }
+	r := newTimeRotator(config)
+	r.now = now
+	r.maxSize = 10
+
+	for i := 0; i < 3; i++ {
+		if _, err := r.Write([]byte("12345678\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.Close()
+	// 关闭时正在写入的文件不压缩
+	want := []string{"app-20261017-0.log.gz", "app-20261017-1.log.gz", "app-20261017-2.log"}
+	if got := listDir(t, dir); !slices.Equal(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+
+	// 重新打开时继续写入未压缩的最后一个文件
+	r = newTimeRotator(config)
+	r.now = now
+	if _, err := r.Write([]byte("again\n")); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if r.name != filepath.Join(dir, "app-20261017-2.log") {
+		t.Fatalf("should continue with the last index, got %s", r.name)
+	}
+}
+
+func TestRotationAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	config := FileLogConfig{FileName: filepath.Join(dir, "app.log"), Rotation: RotationDaily, UTC: true, Compress: true}
+	clock := &fakeClock{now: time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)}
+	r := newTimeRotator(config)
+	r.now = clock.Now
+	if _, err := r.Write([]byte("before restart\n")); err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+
+	// 重启后跨越时间段，上一时间段的文件同样压缩
+	clock.now = clock.now.Add(24 * time.Hour)
+	r = newTimeRotator(config)
+	r.now = clock.Now
+	if _, err := r.Write([]byte("after restart\n")); err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	want := []string{"app-2026-10-17.log.gz", "app-2026-10-18.log"}
+	if got := listDir(t, dir); !slices.Equal(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+
+	// Reopen跨越时间段时同样处理
+	clock.now = clock.now.Add(24 * time.Hour)
+	if err := r.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Write([]byte("after reopen\n")); err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	want = []string{"app-2026-10-17.log.gz", "app-2026-10-18.log.gz", "app-2026-10-19.log"}
+	if got := listDir(t, dir); !slices.Equal(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestTimeRotationConfig(t *testing.T) {
+	resetLoggers(t)
+	dir := t.TempDir()
+	err := InitLoggerE(GlobalConfig{Level: "info"}, ChildConfig{
+		LoggerName: "daily",
+		Level:      "info",
+		Sinks: []SinkConfig{{Type: SinkFile, FileLogConfig: FileLogConfig{
+			FileName: filepath.Join(dir, "daily.log"), Rotation: RotationDaily, UTC: true,
+		}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	GetLoggerWithFileName("daily").Info("hello")
+	name := expandPattern(filepath.Join(dir, "daily-%Y-%m-%d.log"), time.Now().UTC(), 0)
+	if _, err := os.Stat(name); err != nil {
+		t.Fatalf("daily file should be created: %v", err)
+	}
+
+	err = AddChildLoggerE(ChildConfig{LoggerName: "bad", EnableFileLog: true,
+		FileLogConfig: FileLogConfig{FileName: filepath.Join(dir, "bad.log"), Rotation: "weekly"}})
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || configErr.Errors[0].Path != "children[0].rotation" {
+		t.Fatalf("want rotation error, got %v", err)
+	}
+}
@@ -0,0 +1,266 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ConfigDuration 支持"1s"、"500ms"格式的时长配置，json中也可以使用纳秒数
+type ConfigDuration time.Duration
+
+func (d ConfigDuration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *ConfigDuration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = ConfigDuration(parsed)
+	return nil
+}
+
+func (d *ConfigDuration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return d.UnmarshalText([]byte(s))
+	}
+	var n int64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("invalid duration %s", data)
+	}
+	*d = ConfigDuration(n)
+	return nil
+}
+
+// SamplingConfig zap采样配置，每个Tick内同一级别同一消息先输出First条，之后每Thereafter条输出一条；First为0时不采样
+type SamplingConfig struct {
+	Tick       ConfigDuration `yaml:"tick" json:"tick" mapstructure:"tick"`
+	First      int            `yaml:"first" json:"first" mapstructure:"first" validate:"min=0"`
+	Thereafter int            `yaml:"thereafter" json:"thereafter" mapstructure:"thereafter" validate:"min=0"`
+}
+
+// RateLimitConfig 令牌桶限流配置，按logger名称及消息分别限流；Rate为0时不限流
+type RateLimitConfig struct {
+	// Rate 每秒允许输出的条数
+	Rate float64 `yaml:"rate" json:"rate" mapstructure:"rate" validate:"min=0"`
+	// Burst 允许的突发条数，为0时取Rate向上取整
+	Burst int `yaml:"burst" json:"burst" mapstructure:"burst" validate:"min=0"`
+	// SummaryInterval 输出"N messages suppressed"汇总日志的间隔，默认1分钟
+	SummaryInterval ConfigDuration `yaml:"summary_interval" json:"summary_interval" mapstructure:"summary_interval"`
+}
+
+// DropStats 采样、限流及异步缓冲区溢出丢弃的日志条数
+type DropStats struct {
+	Sampled     uint64
+	RateLimited uint64
+	Overflowed  uint64
+}
+
+type dropCounter struct {
+	sampled     atomic.Uint64
+	rateLimited atomic.Uint64
+	overflowed  atomic.Uint64
+}
+
+// GetDropStats 获取logger因采样、限流及缓冲区溢出丢弃的日志条数，name为空时返回全局logger的统计
+func GetDropStats(name string) (DropStats, error) {
+	logLock.RLock()
+	defer logLock.RUnlock()
+	state := globalState
+	if name != "" {
+		var ok bool
+		if state, ok = childStates[name]; !ok {
+			return DropStats{}, fmt.Errorf("log: unknown logger %q", name)
+		}
+	}
+	return DropStats{
+		Sampled:     state.dropped.sampled.Load(),
+		RateLimited: state.dropped.rateLimited.Load(),
+		Overflowed:  state.dropped.overflowed.Load(),
+	}, nil
+}
+
+// applySampling 按配置依次包装采样及限流core
+func applySampling(core zapcore.Core, base ConfigBase, counter *dropCounter, opened *openedSinks) zapcore.Core {
+	if sampling := base.Sampling; sampling.First > 0 {
+		tick := time.Duration(sampling.Tick)
+		if tick <= 0 {
+			tick = time.Second
+		}
+		core = zapcore.NewSamplerWithOptions(core, tick, sampling.First, sampling.Thereafter,
+			zapcore.SamplerHook(func(_ zapcore.Entry, dec zapcore.SamplingDecision) {
+				if dec&zapcore.LogDropped > 0 {
+					counter.sampled.Add(1)
+				}
+			}))
+	}
+	if limit := base.RateLimit; limit.Rate > 0 {
+		limited := newRateLimitCore(core, limit, counter)
+		opened.limiters = append(opened.limiters, limited.limiter)
+		core = limited
+	}
+	return core
+}
+
+type bucket struct {
+	tokens     float64
+	last       time.Time
+	suppressed uint64
+}
+
+type rateLimiter struct {
+	rate     float64
+	burst    float64
+	interval time.Duration
+	counter  *dropCounter
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+
+	once sync.Once
+	quit chan struct{}
+	done chan struct{}
+}
+
+// rateLimitCore 令牌桶限流core，With派生的core共享同一个限流器；
+// 被限流的消息汇总由限流器的后台协程按SummaryInterval定时输出，core被替换时停止
+type rateLimitCore struct {
+	zapcore.Core
+	limiter *rateLimiter
+}
+
+func newRateLimitCore(core zapcore.Core, config RateLimitConfig, counter *dropCounter) *rateLimitCore {
+	burst := float64(config.Burst)
+	if burst <= 0 {
+		burst = float64(int(config.Rate + 0.999999))
+	}
+	interval := time.Duration(config.SummaryInterval)
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	c := &rateLimitCore{Core: core, limiter: &rateLimiter{
+		rate:     config.Rate,
+		burst:    burst,
+		interval: interval,
+		counter:  counter,
+		buckets:  make(map[string]*bucket),
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}}
+	go c.limiter.run(c)
+	return c
+}
+
+func (c *rateLimitCore) With(fields []zapcore.Field) zapcore.Core {
+	return &rateLimitCore{Core: c.Core.With(fields), limiter: c.limiter}
+}
+
+func (c *rateLimitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Core.Enabled(ent.Level) {
+		return ce
+	}
+	if !c.limiter.allow(ent.LoggerName, ent.Message, ent.Time) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+func (c *rateLimitCore) Sync() error {
+	c.writeSummary(c.limiter.summary(time.Now()))
+	return c.Core.Sync()
+}
+
+type suppressedEntry struct {
+	logger, message string
+	count           uint64
+}
+
+// writeSummary 通过底层core输出被限流的消息汇总
+func (c *rateLimitCore) writeSummary(summary []suppressedEntry) {
+	for _, s := range summary {
+		ent := zapcore.Entry{
+			Level:      zapcore.WarnLevel,
+			Time:       time.Now(),
+			LoggerName: s.logger,
+			Message:    fmt.Sprintf("%d messages suppressed", s.count),
+		}
+		if ce := c.Core.Check(ent, nil); ce != nil {
+			ce.Write(String("suppressed_msg", s.message), Uint64("suppressed", s.count))
+		}
+	}
+}
+
+// run 按汇总间隔通过c输出被限流的消息汇总，停止时输出剩余的汇总
+func (l *rateLimiter) run(c *rateLimitCore) {
+	defer close(l.done)
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			c.writeSummary(l.summary(now))
+		case <-l.quit:
+			c.writeSummary(l.summary(time.Now()))
+			return
+		}
+	}
+}
+
+// stop 停止汇总协程，可重复调用
+func (l *rateLimiter) stop() {
+	l.once.Do(func() {
+		close(l.quit)
+	})
+	<-l.done
+}
+
+// allow 消耗令牌，返回是否允许输出
+func (l *rateLimiter) allow(logger, message string, now time.Time) bool {
+	key := logger + "\x00" + message
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = min(l.burst, b.tokens+elapsed*l.rate)
+		b.last = now
+	}
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	} else {
+		b.suppressed++
+		l.counter.rateLimited.Add(1)
+	}
+	return allowed
+}
+
+// summary 收集并清零被限流的消息，同时清理已回满且无积压的令牌桶
+func (l *rateLimiter) summary(now time.Time) []suppressedEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var summary []suppressedEntry
+	for key, b := range l.buckets {
+		if b.suppressed > 0 {
+			logger, message, _ := strings.Cut(key, "\x00")
+			summary = append(summary, suppressedEntry{logger: logger, message: message, count: b.suppressed})
+			b.suppressed = 0
+			continue
+		}
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	return summary
+}
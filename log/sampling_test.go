@@ -0,0 +1,115 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSampling(t *testing.T) {
+	resetLoggers(t)
+	var buf bytes.Buffer
+	registerTestSink(t, "sampling-test-buffer", zapcore.AddSync(&buf))
+	err := InitLoggerE(GlobalConfig{Level: "info"}, ChildConfig{
+		LoggerName: "sampled",
+		Level:      "info",
+		Sinks:      []SinkConfig{{Type: "sampling-test-buffer", Format: FormatJSON}},
+		ConfigBase: ConfigBase{Sampling: SamplingConfig{Tick: ConfigDuration(time.Minute), First: 2, Thereafter: 0}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := GetLoggerWithFileName("sampled")
+	for i := 0; i < 10; i++ {
+		logger.Info("hot path")
+	}
+	if n := strings.Count(buf.String(), "hot path"); n != 2 {
+		t.Fatalf("want 2 sampled entries, got %d", n)
+	}
+	stats, err := GetDropStats("sampled")
+	if err != nil || stats.Sampled != 8 {
+		t.Fatalf("want 8 sampled drops, got %+v (%v)", stats, err)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	resetLoggers(t)
+	buf := &lockedBuffer{}
+	registerTestSink(t, "ratelimit-test-buffer", buf)
+	err := InitLoggerE(GlobalConfig{Level: "info"}, ChildConfig{
+		LoggerName: "limited",
+		Level:      "info",
+		Sinks:      []SinkConfig{{Type: "ratelimit-test-buffer", Format: FormatJSON}},
+		ConfigBase: ConfigBase{RateLimit: RateLimitConfig{Rate: 0.001, Burst: 3, SummaryInterval: ConfigDuration(20 * time.Millisecond)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := GetLoggerWithFileName("limited")
+	for i := 0; i < 10; i++ {
+		logger.Info("flood")
+	}
+	logger.Info("other")
+	output := strings.Join(buf.lines(), "\n")
+	if n := strings.Count(output, `"msg":"flood"`); n != 3 {
+		t.Fatalf("want 3 entries within burst, got %d", n)
+	}
+	if !strings.Contains(output, `"msg":"other"`) {
+		t.Fatal("limits should apply per message")
+	}
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(output, `"msg":"7 messages suppressed"`) {
+		if time.Now().After(deadline) {
+			t.Fatalf("ticker should emit suppression summary without Sync, got %q", output)
+		}
+		time.Sleep(5 * time.Millisecond)
+		output = strings.Join(buf.lines(), "\n")
+	}
+	if !strings.Contains(output, `"suppressed_msg":"flood"`) {
+		t.Fatalf("summary should name the suppressed message, got %q", output)
+	}
+	stats, _ := GetDropStats("limited")
+	if stats.RateLimited != 7 {
+		t.Fatalf("want 7 rate limited drops, got %+v", stats)
+	}
+
+	old := rateLimiters
+	if err := AddChildLoggerE(ChildConfig{LoggerName: "other", Level: "info"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range old {
+		select {
+		case <-l.done:
+		default:
+			t.Fatal("rebuilding cores should stop the old limiters")
+		}
+	}
+}
+
+func TestLoadSamplingConfig(t *testing.T) {
+	config, err := LoadConfig(strings.NewReader(`
+global:
+  level: info
+  sampling:
+    tick: 1s
+    first: 100
+    thereafter: 10
+  rate_limit:
+    rate: 5
+    summary_interval: 30s
+`), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Global.Sampling.Tick != ConfigDuration(time.Second) || config.Global.Sampling.First != 100 {
+		t.Fatalf("unexpected sampling config %+v", config.Global.Sampling)
+	}
+	if config.Global.RateLimit.Rate != 5 || config.Global.RateLimit.SummaryInterval != ConfigDuration(30*time.Second) {
+		t.Fatalf("unexpected rate limit config %+v", config.Global.RateLimit)
+	}
+}
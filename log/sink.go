@@ -0,0 +1,272 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 内置的输出类型
+const (
+	SinkStdout  = "stdout"
+	SinkStderr  = "stderr"
+	SinkFile    = "file"
+	SinkDiscard = "discard"
+)
+
+var (
+	sinkLock    sync.RWMutex
+	customSinks = make(map[string]zapcore.WriteSyncer)
+	coreSinks   = make(map[string]zapcore.Core)
+)
+
+// SinkConfig 单个输出目标配置，每个输出可以使用独立的格式及最低日志级别
+type SinkConfig struct {
+	// Type 输出类型，stdout、stderr、file、discard或通过RegisterSink注册的名称
+	Type string `yaml:"type" json:"type" mapstructure:"type" validate:"required"`
+	// Level 该输出的最低日志级别，为空时仅受logger级别限制
+	Level string `yaml:"level" json:"level" mapstructure:"level" validate:"omitempty,loglevel"`
+	// MaxLevel 该输出的最高日志级别，为空时不限制
+	MaxLevel string `yaml:"max_level" json:"max_level" mapstructure:"max_level" validate:"omitempty,loglevel"`
+	// Format 输出格式，json、console或logfmt，为空时使用logger的格式
+	Format Format `yaml:"format" json:"format" mapstructure:"format" validate:"omitempty,oneof=json console logfmt"`
+	// Color console格式下是否输出带颜色的日志级别
+	Color bool `yaml:"color" json:"color" mapstructure:"color"`
+	// Encoder 该输出的编码配置，与logger的编码配置合并
+	Encoder EncoderConfig `yaml:"encoder" json:"encoder" mapstructure:"encoder"`
+	// FileLogConfig Type为file时的文件及切割配置
+	FileLogConfig `yaml:",inline" mapstructure:",squash"`
+}
+
+// RegisterSink 注册自定义输出，注册后可在SinkConfig.Type中通过name引用；ws的生命周期由调用方管理
+func RegisterSink(name string, ws zapcore.WriteSyncer) error {
+	sinkLock.Lock()
+	defer sinkLock.Unlock()
+	if err := checkSinkName(name); err != nil {
+		return err
+	}
+	customSinks[name] = ws
+	return nil
+}
+
+// RegisterCoreSink 注册自定义core作为输出，用于OTLP等不基于字节流的输出；引用时Format及Encoder配置不生效，
+// 日志级别仍受logger级别及SinkConfig.Level限制，core的生命周期由调用方管理
+func RegisterCoreSink(name string, core zapcore.Core) error {
+	sinkLock.Lock()
+	defer sinkLock.Unlock()
+	if err := checkSinkName(name); err != nil {
+		return err
+	}
+	coreSinks[name] = core
+	return nil
+}
+
+// UnregisterSink 注销通过RegisterSink或RegisterCoreSink注册的输出，注销后名称可重新注册；
+// 已引用该输出的logger继续写入，直到其core因配置变更重建，此后该输出被忽略
+func UnregisterSink(name string) error {
+	sinkLock.Lock()
+	defer sinkLock.Unlock()
+	_, isWriter := customSinks[name]
+	_, isCore := coreSinks[name]
+	if !isWriter && !isCore {
+		return fmt.Errorf("log: sink %q is not registered", name)
+	}
+	delete(customSinks, name)
+	delete(coreSinks, name)
+	return nil
+}
+
+// checkSinkName 检查输出名称是否可用，调用方需持有sinkLock
+func checkSinkName(name string) error {
+	switch name {
+	case "", SinkStdout, SinkStderr, SinkFile, SinkDiscard:
+		return fmt.Errorf("log: sink name %q is reserved", name)
+	}
+	_, isWriter := customSinks[name]
+	_, isCore := coreSinks[name]
+	if isWriter || isCore {
+		return fmt.Errorf("log: sink %q is already registered", name)
+	}
+	return nil
+}
+
+// lookupSink 查找自定义输出
+func lookupSink(name string) (zapcore.WriteSyncer, bool) {
+	sinkLock.RLock()
+	defer sinkLock.RUnlock()
+	ws, ok := customSinks[name]
+	return ws, ok
+}
+
+// lookupCoreSink 查找自定义core输出
+func lookupCoreSink(name string) (zapcore.Core, bool) {
+	sinkLock.RLock()
+	defer sinkLock.RUnlock()
+	core, ok := coreSinks[name]
+	return core, ok
+}
+
+// coreConfig 构建core所需的配置，GlobalConfig与ChildConfig共用
+type coreConfig struct {
+	EnableFileLog bool
+	Sinks         []SinkConfig
+	ConfigBase
+	FileLogConfig
+}
+
+func (c GlobalConfig) coreConfig() coreConfig {
+	return coreConfig{EnableFileLog: c.EnableFileLog, Sinks: outputSinks(c.Outputs, c.Sinks), ConfigBase: c.ConfigBase, FileLogConfig: c.FileLogConfig}
+}
+
+func (c ChildConfig) coreConfig() coreConfig {
+	return coreConfig{EnableFileLog: c.EnableFileLog, Sinks: outputSinks(c.Outputs, c.Sinks), ConfigBase: c.ConfigBase, FileLogConfig: c.FileLogConfig}
+}
+
+// openedSinks 一次重建过程中打开的日志文件及创建的异步写入
+type openedSinks struct {
+	files    map[string]rotatingFile
+	async    []*asyncWriter
+	limiters []*rateLimiter
+}
+
+// writer 按配置为输出附加异步写入
+func (o *openedSinks) writer(ws zapcore.WriteSyncer, config AsyncConfig, counter *dropCounter) zapcore.WriteSyncer {
+	if !config.Enabled {
+		return ws
+	}
+	w := newAsyncWriter(ws, config, counter)
+	o.async = append(o.async, w)
+	return w
+}
+
+// buildCore 根据配置构建core，并按配置附加采样及限流，最后附加通过Observe添加的core
+func buildCore(config coreConfig, state *loggerState, opened *openedSinks) zapcore.Core {
+	// 调用位置仅在输出行号或有observers(可能断言调用位置)时记录
+	state.caller.Store(config.ShowLineNumber || len(observers) > 0)
+	redact := newRedactor(config.Redact)
+	core := applySampling(buildSinks(config, state, opened, redact), config.ConfigBase, &state.dropped, opened)
+	return withObservers(core, state.level, redact)
+}
+
+// buildSinks 构建输出core，未配置Sinks时输出到stdout，开启文件日志时同时写入文件
+func buildSinks(config coreConfig, state *loggerState, opened *openedSinks, redact *redactor) zapcore.Core {
+	level := state.level
+	if len(config.Sinks) == 0 {
+		encoder := newEncoder(config.ConfigBase, SinkConfig{})
+		ws := zapcore.WriteSyncer(stdWriter{os.Stdout})
+		if config.EnableFileLog {
+			ws = zapcore.NewMultiWriteSyncer(ws, zapcore.AddSync(getHooks(config.FileLogConfig, opened.files)))
+		}
+		return redact.wrap(zapcore.NewCore(encoder, opened.writer(ws, config.Async, &state.dropped), level))
+	}
+
+	cores := make([]zapcore.Core, 0, len(config.Sinks))
+	for _, sink := range config.Sinks {
+		if core, ok := lookupCoreSink(sink.Type); ok {
+			cores = append(cores, &levelFilterCore{Core: redact.wrapExternal(core), level: sinkLevel(level, sink.Level, sink.MaxLevel)})
+			continue
+		}
+		ws, ok := sinkWriter(sink, opened)
+		if !ok {
+			continue
+		}
+		ws = opened.writer(ws, config.Async, &state.dropped)
+		core := zapcore.NewCore(newEncoder(config.ConfigBase, sink), ws, sinkLevel(level, sink.Level, sink.MaxLevel))
+		cores = append(cores, redact.wrap(core))
+	}
+	return zapcore.NewTee(cores...)
+}
+
+// OutputConfig 按级别范围分流的文件输出，如全部日志写入app.log、warn及以上同时写入app.error.log，
+// 每个输出使用独立的切割配置，等价于Type为file的SinkConfig
+type OutputConfig struct {
+	// MinLevel 最低日志级别，为空时仅受logger级别限制
+	MinLevel string `yaml:"min_level" json:"min_level" mapstructure:"min_level" validate:"omitempty,loglevel"`
+	// MaxLevel 最高日志级别，为空时不限制
+	MaxLevel string `yaml:"max_level" json:"max_level" mapstructure:"max_level" validate:"omitempty,loglevel"`
+	// Format 输出格式，为空时使用logger的格式
+	Format        Format `yaml:"format" json:"format" mapstructure:"format" validate:"omitempty,oneof=json console logfmt"`
+	FileLogConfig `yaml:",inline" mapstructure:",squash"`
+}
+
+func (o OutputConfig) sink() SinkConfig {
+	return SinkConfig{Type: SinkFile, Level: o.MinLevel, MaxLevel: o.MaxLevel, Format: o.Format, FileLogConfig: o.FileLogConfig}
+}
+
+// outputSinks 合并Outputs及Sinks，Outputs在前
+func outputSinks(outputs []OutputConfig, sinks []SinkConfig) []SinkConfig {
+	if len(outputs) == 0 {
+		return sinks
+	}
+	merged := make([]SinkConfig, 0, len(outputs)+len(sinks))
+	for _, output := range outputs {
+		merged = append(merged, output.sink())
+	}
+	return append(merged, sinks...)
+}
+
+// sinkWriter 获取输出对应的WriteSyncer，未知类型返回false
+func sinkWriter(sink SinkConfig, opened *openedSinks) (zapcore.WriteSyncer, bool) {
+	switch sink.Type {
+	case SinkStdout:
+		return stdWriter{os.Stdout}, true
+	case SinkStderr:
+		return stdWriter{os.Stderr}, true
+	case SinkFile:
+		return zapcore.AddSync(getHooks(sink.FileLogConfig, opened.files)), true
+	case SinkDiscard:
+		return zapcore.AddSync(io.Discard), true
+	}
+	return lookupSink(sink.Type)
+}
+
+// stdWriter 标准输出及标准错误没有缓冲，Sync时忽略终端及管道不支持fsync的错误
+type stdWriter struct {
+	*os.File
+}
+
+func (stdWriter) Sync() error {
+	return nil
+}
+
+// sinkLevel 同时满足logger级别及输出的级别范围时才输出
+func sinkLevel(level zapcore.LevelEnabler, min, max string) zapcore.LevelEnabler {
+	if min == "" && max == "" {
+		return level
+	}
+	minLevel, maxLevel := zapcore.DebugLevel, zapcore.FatalLevel
+	if min != "" {
+		minLevel = getLogLevel(min)
+	}
+	if max != "" {
+		maxLevel = getLogLevel(max)
+	}
+	return zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return l >= minLevel && l <= maxLevel && level.Enabled(l)
+	})
+}
+
+// levelFilterCore 在core自身级别之外额外限制日志级别
+type levelFilterCore struct {
+	zapcore.Core
+	level zapcore.LevelEnabler
+}
+
+func (c *levelFilterCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level) && c.Core.Enabled(level)
+}
+
+func (c *levelFilterCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelFilterCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c *levelFilterCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.level.Enabled(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
@@ -0,0 +1,77 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSinks(t *testing.T) {
+	resetLoggers(t)
+	dir := t.TempDir()
+	var buf bytes.Buffer
+	registerTestSink(t, "sink-test-buffer", zapcore.AddSync(&buf))
+	if err := RegisterSink(SinkStdout, zapcore.AddSync(&buf)); err == nil {
+		t.Fatal("reserved sink name should be rejected")
+	}
+
+	errFile := filepath.Join(dir, "error.log")
+	err := InitLoggerE(GlobalConfig{Level: "info"}, ChildConfig{
+		LoggerName: "orders",
+		Level:      "debug",
+		Sinks: []SinkConfig{
+			{Type: "sink-test-buffer", Format: "json"},
+			{Type: SinkFile, Level: "warn", FileLogConfig: FileLogConfig{FileName: errFile}},
+			{Type: SinkDiscard},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orders := GetLoggerWithFileName("orders")
+	orders.Debug("debug entry")
+	orders.Warn("warn entry")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("buffer sink got %d lines: %q", len(lines), buf.String())
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil || entry["msg"] != "debug entry" {
+		t.Fatalf("buffer sink should receive json, got %q (%v)", lines[0], err)
+	}
+	content, _ := os.ReadFile(errFile)
+	if strings.Contains(string(content), "debug entry") || !strings.Contains(string(content), "warn entry") {
+		t.Fatalf("file sink should only keep warn and above, got %q", content)
+	}
+
+	err = AddChildLoggerE(ChildConfig{LoggerName: "bad", Sinks: []SinkConfig{{Type: "kafka"}, {Type: SinkFile}}})
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || len(configErr.Errors) != 2 {
+		t.Fatalf("want 2 sink errors, got %v", err)
+	}
+}
+
+func TestUnregisterSink(t *testing.T) {
+	if err := RegisterCoreSink("unregister-test-core", zapcore.NewNopCore()); err != nil {
+		t.Fatal(err)
+	}
+	if err := UnregisterSink("unregister-test-core"); err != nil {
+		t.Fatal(err)
+	}
+	if err := UnregisterSink("unregister-test-core"); err == nil {
+		t.Fatal("unregistering an unknown sink should fail")
+	}
+	if err := RegisterSink("unregister-test-core", zapcore.AddSync(io.Discard)); err != nil {
+		t.Fatalf("name should be reusable after unregistering: %v", err)
+	}
+	UnregisterSink("unregister-test-core")
+}
@@ -0,0 +1,171 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SlogHandler 基于gbase Logger的slog.Handler，日志经由Logger的core输出，配置热加载后同样生效
+type SlogHandler struct {
+	core zapcore.Core
+	name string
+	// groups 尚未输出的分组，只有分组内存在属性时才以Namespace的形式写入，与slog忽略空分组的约定一致
+	groups []string
+	// scoped 分组内通过WithAttrs添加的属性(以Namespace开头)，每条日志在context字段之后写入，
+	// 使context字段保持在顶层
+	scoped []zapcore.Field
+}
+
+// NewSlogHandler 创建写入logger的slog.Handler
+func NewSlogHandler(logger *Logger) *SlogHandler {
+	return &SlogHandler{core: logger.l.Core(), name: logger.l.Name()}
+}
+
+// NewSlog 返回绑定到logger的*slog.Logger，name为空时使用全局logger，否则使用Get(name)返回的logger
+func NewSlog(name string) *slog.Logger {
+	return slog.New(NewSlogHandler(Get(name)))
+}
+
+// SetSlogDefault 将绑定到logger的*slog.Logger设置为slog的默认logger，name的含义与NewSlog一致
+func SetSlogDefault(name string) *slog.Logger {
+	logger := NewSlog(name)
+	slog.SetDefault(logger)
+	return logger
+}
+
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.core.Enabled(slogLevel(level))
+}
+
+// Handle 输出日志，context中可提取的字段通过RegisterContextExtractor注册的提取器附加到顶层
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	ent := zapcore.Entry{
+		Level:      slogLevel(record.Level),
+		Time:       record.Time,
+		Message:    record.Message,
+		LoggerName: h.name,
+	}
+	ce := h.core.Check(ent, nil)
+	if ce == nil {
+		return nil
+	}
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		ce.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+		ce.Caller.Function = frame.Function
+	}
+
+	fields := contextFields(ctx, make([]zapcore.Field, 0, record.NumAttrs()+len(h.groups)+len(h.scoped)))
+	fields = append(fields, h.scoped...)
+	if record.NumAttrs() > 0 {
+		fields = h.appendGroups(fields)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		fields = appendAttr(fields, attr)
+		return true
+	})
+	ce.Write(fields...)
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	if len(h.groups) == 0 && len(h.scoped) == 0 {
+		fields := make([]zapcore.Field, 0, len(attrs))
+		for _, attr := range attrs {
+			fields = appendAttr(fields, attr)
+		}
+		return &SlogHandler{core: h.core.With(fields), name: h.name}
+	}
+	scoped := make([]zapcore.Field, 0, len(h.scoped)+len(h.groups)+len(attrs))
+	scoped = h.appendGroups(append(scoped, h.scoped...))
+	for _, attr := range attrs {
+		scoped = appendAttr(scoped, attr)
+	}
+	return &SlogHandler{core: h.core, name: h.name, scoped: scoped}
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := make([]string, 0, len(h.groups)+1)
+	groups = append(groups, h.groups...)
+	return &SlogHandler{core: h.core, name: h.name, groups: append(groups, name), scoped: h.scoped}
+}
+
+func (h *SlogHandler) appendGroups(fields []zapcore.Field) []zapcore.Field {
+	for _, group := range h.groups {
+		fields = append(fields, zap.Namespace(group))
+	}
+	return fields
+}
+
+// slogLevel slog级别映射为zap级别，高于Error的级别按Error处理
+func slogLevel(level slog.Level) zapcore.Level {
+	switch {
+	case level < slog.LevelInfo:
+		return zapcore.DebugLevel
+	case level < slog.LevelWarn:
+		return zapcore.InfoLevel
+	case level < slog.LevelError:
+		return zapcore.WarnLevel
+	}
+	return zapcore.ErrorLevel
+}
+
+// appendAttr 将slog属性转换为zap字段，空属性忽略，key为空的分组平铺到上一层
+func appendAttr(fields []zapcore.Field, attr slog.Attr) []zapcore.Field {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+	value := attr.Value
+	switch value.Kind() {
+	case slog.KindBool:
+		return append(fields, zap.Bool(attr.Key, value.Bool()))
+	case slog.KindDuration:
+		return append(fields, zap.Duration(attr.Key, value.Duration()))
+	case slog.KindFloat64:
+		return append(fields, zap.Float64(attr.Key, value.Float64()))
+	case slog.KindInt64:
+		return append(fields, zap.Int64(attr.Key, value.Int64()))
+	case slog.KindString:
+		return append(fields, zap.String(attr.Key, value.String()))
+	case slog.KindTime:
+		return append(fields, zap.Time(attr.Key, value.Time()))
+	case slog.KindUint64:
+		return append(fields, zap.Uint64(attr.Key, value.Uint64()))
+	case slog.KindGroup:
+		group := value.Group()
+		if len(group) == 0 {
+			return fields
+		}
+		if attr.Key == "" {
+			for _, a := range group {
+				fields = appendAttr(fields, a)
+			}
+			return fields
+		}
+		return append(fields, zap.Object(attr.Key, slogGroup(group)))
+	}
+	if err, ok := value.Any().(error); ok {
+		return append(fields, zap.NamedError(attr.Key, err))
+	}
+	return append(fields, zap.Any(attr.Key, value.Any()))
+}
+
+type slogGroup []slog.Attr
+
+func (g slogGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, field := range appendAttr(nil, slog.Attr{Value: slog.GroupValue(g...)}) {
+		field.AddTo(enc)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSlogHandler(t *testing.T) {
+	resetLoggers(t)
+	var buf bytes.Buffer
+	registerTestSink(t, "slog-test-buffer", zapcore.AddSync(&buf))
+	err := InitLoggerE(GlobalConfig{Level: "info"}, ChildConfig{
+		LoggerName: "payments",
+		Level:      "debug",
+		ConfigBase: ConfigBase{Format: FormatJSON, ShowLineNumber: true},
+		Sinks:      []SinkConfig{{Type: "slog-test-buffer"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := NewSlog("payments").With("service", "billing").WithGroup("req").WithGroup("empty")
+	logger.Debug("charged",
+		"amount", 12.5,
+		slog.Group("card", "brand", "visa", "last4", "4242"),
+		slog.Group("", "inline", true),
+		"took", 15*time.Millisecond,
+		"err", errors.New("declined"),
+	)
+	NewSlog("payments").WithGroup("unused").Info("no attrs")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("want 2 lines, got %q", buf.String())
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["level"] != "DEBUG" || entry["logger"] != "payments" || entry["service"] != "billing" {
+		t.Fatalf("unexpected entry %v", entry)
+	}
+	if caller, _ := entry["caller"].(string); !strings.HasPrefix(caller, "log/slog_test.go:") {
+		t.Fatalf("caller should point at the slog call site, got %v", entry["caller"])
+	}
+	group := entry["req"].(map[string]interface{})["empty"].(map[string]interface{})
+	card := group["card"].(map[string]interface{})
+	if group["amount"] != 12.5 || card["brand"] != "visa" || group["inline"] != true || group["err"] != "declined" {
+		t.Fatalf("unexpected group %v", group)
+	}
+	if strings.Contains(lines[1], "unused") {
+		t.Fatalf("empty group should be omitted, got %s", lines[1])
+	}
+
+	if NewSlog("payments").Enabled(context.Background(), slog.LevelDebug-1) != true {
+		t.Fatal("levels below debug should map to debug")
+	}
+	if NewSlog("").Enabled(context.Background(), slog.LevelDebug) {
+		t.Fatal("global logger is at info level")
+	}
+}
+
+func TestSlogContextFieldsInGroup(t *testing.T) {
+	resetLoggers(t)
+	buf := &lockedBuffer{}
+	registerTestSink(t, "slog-test-group", buf)
+	err := InitLoggerE(GlobalConfig{Level: "info", ConfigBase: ConfigBase{Format: FormatJSON}, Sinks: []SinkConfig{{Type: "slog-test-group"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := ContextWithTraceID(context.Background(), "abc")
+	NewSlog("").With("top", 0).WithGroup("g").With("a", 1).WithGroup("h").With("b", 2).InfoContext(ctx, "grouped", "c", 3)
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(buf.lines()[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	g, _ := entry["g"].(map[string]any)
+	h, _ := g["h"].(map[string]any)
+	if entry["trace_id"] != "abc" || entry["top"] != float64(0) || g["trace_id"] != nil {
+		t.Fatalf("context fields should stay at the top level, got %v", entry)
+	}
+	if g["a"] != float64(1) || h["b"] != float64(2) || h["c"] != float64(3) {
+		t.Fatalf("grouped attrs should stay in their groups, got %v", entry)
+	}
+}
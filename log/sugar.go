@@ -0,0 +1,135 @@
+package log
+
+import (
+	"go.uber.org/zap"
+)
+
+// SugaredLogger zap的SugaredLogger，支持printf风格及键值对形式的日志
+type SugaredLogger = zap.SugaredLogger
+
+// Sugar 返回全局logger对应的SugaredLogger
+func Sugar() *SugaredLogger {
+	return GetLogger().Sugar()
+}
+
+// Sugar 返回对应的SugaredLogger，与Logger共用级别及输出配置，调用位置指向SugaredLogger的调用方；
+// 返回的SugaredLogger始终记录调用位置，是否输出由配置决定
+func (log *Logger) Sugar() *SugaredLogger {
+	return log.l.WithOptions(zap.AddCallerSkip(-1)).Sugar()
+}
+
+// 以下为printf风格的函数，按fmt.Sprintf格式化消息，仅在对应级别开启时才执行格式化
+
+func Debugf(template string, args ...any) {
+	GetLogger().sugar().Debugf(template, args...)
+}
+
+func Infof(template string, args ...any) {
+	GetLogger().sugar().Infof(template, args...)
+}
+
+func Warnf(template string, args ...any) {
+	GetLogger().sugar().Warnf(template, args...)
+}
+
+func Errorf(template string, args ...any) {
+	GetLogger().sugar().Errorf(template, args...)
+}
+
+func DPanicf(template string, args ...any) {
+	GetLogger().sugar().DPanicf(template, args...)
+}
+
+func Panicf(template string, args ...any) {
+	GetLogger().sugar().Panicf(template, args...)
+}
+
+func Fatalf(template string, args ...any) {
+	GetLogger().sugar().Fatalf(template, args...)
+}
+
+func (log *Logger) Debugf(template string, args ...any) {
+	log.sugar().Debugf(template, args...)
+}
+
+func (log *Logger) Infof(template string, args ...any) {
+	log.sugar().Infof(template, args...)
+}
+
+func (log *Logger) Warnf(template string, args ...any) {
+	log.sugar().Warnf(template, args...)
+}
+
+func (log *Logger) Errorf(template string, args ...any) {
+	log.sugar().Errorf(template, args...)
+}
+
+func (log *Logger) DPanicf(template string, args ...any) {
+	log.sugar().DPanicf(template, args...)
+}
+
+func (log *Logger) Panicf(template string, args ...any) {
+	log.sugar().Panicf(template, args...)
+}
+
+func (log *Logger) Fatalf(template string, args ...any) {
+	log.sugar().Fatalf(template, args...)
+}
+
+// 以下为键值对形式的函数，如Infow("request failed", "url", url, "status", 500)，也可以直接传入Field
+
+func Debugw(msg string, keysAndValues ...any) {
+	GetLogger().sugar().Debugw(msg, keysAndValues...)
+}
+
+func Infow(msg string, keysAndValues ...any) {
+	GetLogger().sugar().Infow(msg, keysAndValues...)
+}
+
+func Warnw(msg string, keysAndValues ...any) {
+	GetLogger().sugar().Warnw(msg, keysAndValues...)
+}
+
+func Errorw(msg string, keysAndValues ...any) {
+	GetLogger().sugar().Errorw(msg, keysAndValues...)
+}
+
+func DPanicw(msg string, keysAndValues ...any) {
+	GetLogger().sugar().DPanicw(msg, keysAndValues...)
+}
+
+func Panicw(msg string, keysAndValues ...any) {
+	GetLogger().sugar().Panicw(msg, keysAndValues...)
+}
+
+func Fatalw(msg string, keysAndValues ...any) {
+	GetLogger().sugar().Fatalw(msg, keysAndValues...)
+}
+
+func (log *Logger) Debugw(msg string, keysAndValues ...any) {
+	log.sugar().Debugw(msg, keysAndValues...)
+}
+
+func (log *Logger) Infow(msg string, keysAndValues ...any) {
+	log.sugar().Infow(msg, keysAndValues...)
+}
+
+func (log *Logger) Warnw(msg string, keysAndValues ...any) {
+	log.sugar().Warnw(msg, keysAndValues...)
+}
+
+func (log *Logger) Errorw(msg string, keysAndValues ...any) {
+	log.sugar().Errorw(msg, keysAndValues...)
+}
+
+func (log *Logger) DPanicw(msg string, keysAndValues ...any) {
+	log.sugar().DPanicw(msg, keysAndValues...)
+}
+
+func (log *Logger) Panicw(msg string, keysAndValues ...any) {
+	log.sugar().Panicw(msg, keysAndValues...)
+}
+
+func (log *Logger) Fatalw(msg string, keysAndValues ...any) {
+	log.sugar().Fatalw(msg, keysAndValues...)
+}
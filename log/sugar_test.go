@@ -0,0 +1,64 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSugarAndCaller(t *testing.T) {
+	resetLoggers(t)
+	buf := &lockedBuffer{}
+	registerTestSink(t, "sugar-test-buffer", buf)
+	InitLogger(GlobalConfig{
+		Level:      "info",
+		ConfigBase: ConfigBase{ShowLineNumber: true},
+		Sinks:      []SinkConfig{{Type: "sugar-test-buffer", Format: FormatJSON}},
+	})
+
+	logger := Get("orders")
+	Info("structured")
+	InfoCtx(context.Background(), "structured ctx")
+	Infof("order %d", 1)
+	Debugf("filtered %d", 2)
+	Infow("paid", "order", 1, String("currency", "CNY"))
+	logger.Errorf("order %d failed", 3)
+	logger.Warnw("slow", "ms", 1200)
+	logger.InfoCtx(context.Background(), "method ctx")
+	Sugar().Infof("sugar %s", "global")
+	logger.Sugar().Infow("sugar child", "order", 4)
+
+	lines := buf.lines()
+	want := []string{"structured", "structured ctx", "order 1", "paid", "order 3 failed", "slow", "method ctx", "sugar global", "sugar child"}
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d entries, got %q", len(want), lines)
+	}
+	for i, line := range lines {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+		if entry["msg"] != want[i] {
+			t.Fatalf("entry %d: expected message %q, got %v", i, want[i], entry["msg"])
+		}
+		if caller, _ := entry["caller"].(string); !strings.HasPrefix(caller, "log/sugar_test.go:") {
+			t.Fatalf("entry %q: caller should point at the call site, got %v", want[i], entry["caller"])
+		}
+	}
+	if !strings.Contains(lines[3], `"order":1`) || !strings.Contains(lines[3], `"currency":"CNY"`) ||
+		!strings.Contains(lines[5], `"logger":"orders"`) || !strings.Contains(lines[5], `"ms":1200`) {
+		t.Fatalf("unexpected key-value fields: %q", lines)
+	}
+}
+
+func TestSugarIsLazy(t *testing.T) {
+	logger := GetLogger().With(String("k", "v")).Named("lazy")
+	if logger.s != nil || logger.plainS != nil {
+		t.Fatal("With and Named should not build a SugaredLogger")
+	}
+	logger.Debugf("built on first use %d", 1)
+	if logger.s == nil || logger.plainS == nil {
+		t.Fatal("SugaredLogger should be built on first use")
+	}
+}
@@ -0,0 +1,277 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	playground "github.com/go-playground/validator/v10"
+	"github.com/kitdine/gbase/validator"
+)
+
+var configValidator = newConfigValidator()
+
+func newConfigValidator() *playground.Validate {
+	v := validator.New()
+	v.RegisterValidation("loglevel", func(fl playground.FieldLevel) bool {
+		_, err := ParseLevel(fl.Field().String())
+		return err == nil
+	})
+	return v
+}
+
+// FieldError 单个配置字段的校验错误
+type FieldError struct {
+	// Logger 子logger名称，全局logger为空
+	Logger string
+	// Path 与配置文件一致的字段路径，如global.level、children[0].file_name
+	Path   string
+	Value  interface{}
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("log: %s: %s", e.Path, e.Reason)
+}
+
+// ConfigError 配置校验失败时返回，包含全部非法字段
+type ConfigError struct {
+	Errors []*FieldError
+}
+
+func (e *ConfigError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e *ConfigError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		errs = append(errs, fe)
+	}
+	return errs
+}
+
+// validateConfig 校验配置，existing为已注册的子logger，名称与其重复视为错误；返回*ConfigError或nil
+//
+// existing不为nil时为向已初始化的logger添加子logger，继承的配置取自当前的全局及子logger配置，调用方需持有logLock
+func validateConfig(config FileConfig, existing map[string]ChildConfig) error {
+	var errs []*FieldError
+	err := configValidator.Struct(config)
+	var fieldErrs playground.ValidationErrors
+	if errors.As(err, &fieldErrs) {
+		for _, fe := range fieldErrs {
+			path := fieldPath(fe)
+			fieldErr := &FieldError{Path: path, Value: fe.Value(), Reason: describeFieldError(fe)}
+			if strings.HasPrefix(path, "children[") {
+				var i int
+				fmt.Sscanf(path, "children[%d]", &i)
+				fieldErr.Logger = config.Children[i].LoggerName
+			}
+			errs = append(errs, fieldErr)
+		}
+	} else if err != nil {
+		return err
+	}
+
+	errs = append(errs, checkFileLog("global", "", config.Global.EnableFileLog, config.Global.FileLogConfig)...)
+	errs = append(errs, checkSinks("global", "", config.Global.Sinks)...)
+	errs = append(errs, checkOutputs("global", "", config.Global.Outputs)...)
+	errs = append(errs, checkRedact("global", "", config.Global.Redact)...)
+	for i, rule := range config.Global.LevelRules {
+		if _, err := parseLevelRule(rule); err != nil {
+			errs = append(errs, &FieldError{Path: fmt.Sprintf("global.level_rules[%d]", i), Value: rule,
+				Reason: strings.TrimPrefix(err.Error(), "log: ")})
+		}
+	}
+	global, children := config.Global, make(map[string]ChildConfig, len(existing)+len(config.Children))
+	if existing != nil {
+		global = globalConfig
+	}
+	for name, child := range existing {
+		children[name] = child
+	}
+	for _, child := range config.Children {
+		children[child.LoggerName] = child
+	}
+	seen := make(map[string]bool, len(config.Children))
+	names := inheritable()
+	for i, child := range config.Children {
+		child = resolveChildConfig(child, global, children)
+		path := fmt.Sprintf("children[%d]", i)
+		for j, name := range child.NoInherit {
+			if !names[name] {
+				errs = append(errs, &FieldError{Logger: child.LoggerName, Path: fmt.Sprintf("%s.no_inherit[%d]", path, j), Value: name,
+					Reason: fmt.Sprintf("unknown field %q", name)})
+			}
+		}
+		if child.LoggerName != "" {
+			if _, ok := existing[child.LoggerName]; ok {
+				errs = append(errs, &FieldError{Logger: child.LoggerName, Path: path + ".logger_name", Value: child.LoggerName,
+					Reason: fmt.Sprintf("logger %q is already registered", child.LoggerName)})
+			} else if seen[child.LoggerName] {
+				errs = append(errs, &FieldError{Logger: child.LoggerName, Path: path + ".logger_name", Value: child.LoggerName,
+					Reason: fmt.Sprintf("duplicate logger name %q", child.LoggerName)})
+			}
+			seen[child.LoggerName] = true
+		}
+		errs = append(errs, checkFileLog(path, child.LoggerName, child.EnableFileLog, child.FileLogConfig)...)
+		errs = append(errs, checkSinks(path, child.LoggerName, child.Sinks)...)
+		errs = append(errs, checkOutputs(path, child.LoggerName, child.Outputs)...)
+		errs = append(errs, checkRedact(path, child.LoggerName, child.Redact)...)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ConfigError{Errors: errs}
+}
+
+// checkFileLog 开启文件日志时检查文件名及所在目录是否可写
+func checkFileLog(path, logger string, enableFileLog bool, config FileLogConfig) []*FieldError {
+	if !enableFileLog {
+		return nil
+	}
+	if config.FileName == "" {
+		return []*FieldError{{Logger: logger, Path: path + ".file_name", Value: config.FileName,
+			Reason: "is required when enable_file_log is true"}}
+	}
+	dir := filepath.Dir(config.FileName)
+	if config.rotation() != RotationSize {
+		dir = filepath.Dir(filePattern(config))
+	}
+	var errs []*FieldError
+	if err := checkWritable(dir); err != nil {
+		errs = append(errs, &FieldError{Logger: logger, Path: path + ".file_name", Value: config.FileName,
+			Reason: fmt.Sprintf("directory is not writable: %v", err)})
+	}
+	if config.ArchiveDir != "" {
+		if err := checkWritable(config.ArchiveDir); err != nil {
+			errs = append(errs, &FieldError{Logger: logger, Path: path + ".archive_dir", Value: config.ArchiveDir,
+				Reason: fmt.Sprintf("directory is not writable: %v", err)})
+		}
+	}
+	if _, ok := lookupObjectStore(config.Upload); config.Upload != "" && !ok {
+		errs = append(errs, &FieldError{Logger: logger, Path: path + ".upload", Value: config.Upload,
+			Reason: fmt.Sprintf("unknown object store %q, register it with RegisterObjectStore first", config.Upload)})
+	}
+	return errs
+}
+
+// checkSinks 检查输出类型是否存在，文件输出检查文件名及目录
+func checkSinks(path, logger string, sinks []SinkConfig) []*FieldError {
+	var errs []*FieldError
+	for i, sink := range sinks {
+		sinkPath := fmt.Sprintf("%s.sinks[%d]", path, i)
+		switch sink.Type {
+		case "", SinkStdout, SinkStderr, SinkDiscard:
+		case SinkFile:
+			errs = append(errs, checkFileLog(sinkPath, logger, true, sink.FileLogConfig)...)
+		default:
+			_, isWriter := lookupSink(sink.Type)
+			_, isCore := lookupCoreSink(sink.Type)
+			if !isWriter && !isCore {
+				errs = append(errs, &FieldError{Logger: logger, Path: sinkPath + ".type", Value: sink.Type,
+					Reason: fmt.Sprintf("unknown sink type %q, register it with RegisterSink or RegisterCoreSink first", sink.Type)})
+			}
+		}
+		errs = append(errs, checkLevelRange(sinkPath, logger, sink.Level, sink.MaxLevel)...)
+	}
+	return errs
+}
+
+// checkOutputs 检查分流输出的文件配置及级别范围
+func checkOutputs(path, logger string, outputs []OutputConfig) []*FieldError {
+	var errs []*FieldError
+	for i, output := range outputs {
+		outputPath := fmt.Sprintf("%s.outputs[%d]", path, i)
+		errs = append(errs, checkFileLog(outputPath, logger, true, output.FileLogConfig)...)
+		errs = append(errs, checkLevelRange(outputPath, logger, output.MinLevel, output.MaxLevel)...)
+	}
+	return errs
+}
+
+// checkRedact 检查脱敏的字段值规则是否为内置规则或合法的正则表达式
+func checkRedact(path, logger string, config RedactConfig) []*FieldError {
+	var errs []*FieldError
+	for i, pattern := range config.Patterns {
+		if _, err := compileRedactPattern(pattern); err != nil {
+			errs = append(errs, &FieldError{Logger: logger, Path: fmt.Sprintf("%s.redact.patterns[%d]", path, i), Value: pattern,
+				Reason: fmt.Sprintf("invalid pattern: %v", err)})
+		}
+	}
+	return errs
+}
+
+// checkLevelRange 检查最高级别不低于最低级别，级别本身是否合法由loglevel校验
+func checkLevelRange(path, logger, min, max string) []*FieldError {
+	if min == "" || max == "" {
+		return nil
+	}
+	minLevel, minErr := ParseLevel(min)
+	maxLevel, maxErr := ParseLevel(max)
+	if minErr != nil || maxErr != nil || maxLevel >= minLevel {
+		return nil
+	}
+	return []*FieldError{{Logger: logger, Path: path + ".max_level", Value: max,
+		Reason: fmt.Sprintf("must not be lower than %q", min)}}
+}
+
+// checkWritable 检查目录是否可写，目录不存在时检查最近的已存在上级目录，与lumberjack自动创建目录的行为一致
+func checkWritable(dir string) error {
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", dir)
+			}
+			break
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return err
+		}
+		dir = parent
+	}
+	probe, err := os.CreateTemp(dir, ".gbase-log-probe-*")
+	if err != nil {
+		return err
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// fieldPath 去掉顶层结构体名称及嵌入结构体名称，得到与配置文件一致的字段路径
+func fieldPath(fe playground.FieldError) string {
+	parts := strings.Split(fe.Namespace(), ".")[1:]
+	path := parts[:0]
+	for _, part := range parts {
+		if part == "ConfigBase" || part == "FileLogConfig" {
+			continue
+		}
+		path = append(path, part)
+	}
+	return strings.Join(path, ".")
+}
+
+func describeFieldError(fe playground.FieldError) string {
+	switch fe.Tag() {
+	case "loglevel":
+		return fmt.Sprintf("unknown level %q, must be one of %s", fe.Value(), strings.Join(levelNames, ", "))
+	case "required":
+		return "is required"
+	case "oneof":
+		return fmt.Sprintf("unknown value %q, must be one of %s", fe.Value(), strings.Join(strings.Fields(fe.Param()), ", "))
+	case "min":
+		return fmt.Sprintf("must be >= %s, got %v", fe.Param(), fe.Value())
+	}
+	return fmt.Sprintf("failed on %q validation", fe.Tag())
+}
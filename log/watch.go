@@ -0,0 +1,107 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WatchInterval 配置文件轮询间隔
+var WatchInterval = 2 * time.Second
+
+// Watcher 日志配置文件监听器
+type Watcher struct {
+	path    string
+	modTime time.Time
+	size    int64
+	content []byte
+	// children 上次应用的配置中的子logger名称
+	children map[string]bool
+
+	stop chan struct{}
+	once sync.Once
+	done chan struct{}
+}
+
+// Watch 加载配置文件初始化日志，并在文件变化后重新应用配置，支持yaml、json、toml格式
+//
+// 重新加载时全局logger及文件中列出的子logger原地替换core，已获取的Logger无需重新获取，
+// 未变化的日志文件不会重新打开；从文件中删除的子logger被移除，此后按全局logger的级别及输出写入；
+// 文件解析失败时保留当前配置并输出错误日志
+func Watch(path string) (*Watcher, error) {
+	w := &Watcher{
+		path: path,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if _, err := w.reload(); err != nil {
+		return nil, err
+	}
+	go w.run()
+	return w, nil
+}
+
+// Close 停止监听配置文件
+func (w *Watcher) Close() {
+	w.once.Do(func() {
+		close(w.stop)
+	})
+	<-w.done
+}
+
+func (w *Watcher) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(WatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if _, err := w.reload(); err != nil {
+				Error("reload log config failed", String("path", w.path), Err(err))
+			}
+		}
+	}
+}
+
+// reload 文件内容变化时重新应用配置，返回是否发生了重新加载
+func (w *Watcher) reload() (bool, error) {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return false, err
+	}
+	if w.content != nil && info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return false, nil
+	}
+	content, err := os.ReadFile(w.path)
+	if err != nil {
+		return false, err
+	}
+	w.modTime, w.size = info.ModTime(), info.Size()
+	if w.content != nil && bytes.Equal(content, w.content) {
+		return false, nil
+	}
+	config, err := LoadConfig(bytes.NewReader(content), strings.TrimPrefix(filepath.Ext(w.path), "."))
+	if err != nil {
+		return false, err
+	}
+	children := make(map[string]bool, len(config.Children))
+	for _, child := range config.Children {
+		children[child.LoggerName] = true
+	}
+	var removed []string
+	for name := range w.children {
+		if !children[name] {
+			removed = append(removed, name)
+		}
+	}
+	if err := reloadLogger(config, removed); err != nil {
+		return false, err
+	}
+	w.content, w.children = content, children
+	return true, nil
+}
@@ -0,0 +1,111 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchReload(t *testing.T) {
+	resetLoggers(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.yaml")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`
+global:
+  level: info
+children:
+  - logger_name: orders
+    level: info
+    enable_file_log: true
+    file_name: ` + filepath.Join(dir, "orders.log") + `
+`)
+
+	interval := WatchInterval
+	WatchInterval = 10 * time.Millisecond
+	defer func() { WatchInterval = interval }()
+
+	w, err := Watch(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	orders := GetLoggerWithFileName("orders")
+	logLock.RLock()
+	hook := files[filepath.Join(dir, "orders.log")]
+	logLock.RUnlock()
+
+	// 保证修改时间发生变化
+	time.Sleep(20 * time.Millisecond)
+	write(`
+global:
+  level: warn
+children:
+  - logger_name: orders
+    level: debug
+    enable_file_log: true
+    file_name: ` + filepath.Join(dir, "orders.log") + `
+`)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if level, _ := GetLevel("orders"); level == "debug" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("config was not reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if level, _ := GetLevel(""); level != "warn" {
+		t.Fatalf("global level = %s, want warn", level)
+	}
+	orders.Debug("debug after reload")
+
+	logLock.RLock()
+	reused := files[filepath.Join(dir, "orders.log")] == hook
+	logLock.RUnlock()
+	if !reused {
+		t.Fatal("unchanged log file should not be reopened")
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "orders.log"))
+	if err != nil || len(content) == 0 {
+		t.Fatalf("orders.log should contain the debug entry, err=%v", err)
+	}
+
+	// 从文件中删除子logger后，该logger恢复为全局logger的级别及输出，日志文件被关闭
+	time.Sleep(20 * time.Millisecond)
+	write(`
+global:
+  level: warn
+`)
+	deadline = time.Now().Add(2 * time.Second)
+	for {
+		if _, err := GetLevel("orders"); err != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("removed child logger was not removed on reload")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if children := ListChildLoggers(); len(children) != 0 {
+		t.Fatalf("removed child logger should not be listed, got %v", children)
+	}
+	logLock.RLock()
+	_, open := files[filepath.Join(dir, "orders.log")]
+	logLock.RUnlock()
+	if open {
+		t.Fatal("log file of the removed child logger should be closed")
+	}
+	orders.Debug("debug after removal")
+	if content, _ := os.ReadFile(filepath.Join(dir, "orders.log")); strings.Contains(string(content), "debug after removal") {
+		t.Fatal("removed child logger should follow the global level and outputs")
+	}
+}
@@ -0,0 +1,44 @@
+// Package validator 基于go-playground/validator的结构体校验工具，字段名优先使用json标签
+package validator
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	once     sync.Once
+	validate *validator.Validate
+)
+
+// New 创建校验器，校验错误中的字段名优先使用json标签
+func New() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			return ""
+		case "":
+			return field.Name
+		}
+		return name
+	})
+	return v
+}
+
+// Default 返回全局共享的校验器
+func Default() *validator.Validate {
+	once.Do(func() {
+		validate = New()
+	})
+	return validate
+}
+
+// Struct 使用全局校验器校验结构体
+func Struct(s interface{}) error {
+	return Default().Struct(s)
+}